@@ -0,0 +1,143 @@
+package urlstruct_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type BenchSmallFilter struct {
+	urlstruct.Pager
+	AuthorID int64
+}
+
+type BenchMediumFilter struct {
+	AuthorID     int64
+	Title        string
+	Status       []string
+	Tags         []string
+	CreatedAtGTE time.Time
+	CreatedAtLT  time.Time
+	PriceGTE     float64
+	PriceLTE     float64
+	Published    bool
+	IDs          []int64
+}
+
+type BenchLargeFilter struct {
+	BenchMediumFilter
+	Author StructMap
+
+	Field    string
+	FieldNEQ string
+	FieldLT  int8
+	FieldLTE int16
+	FieldGT  int32
+	FieldGTE int64
+
+	Multi    []string
+	MultiNEQ []int
+
+	Map    map[string]string
+	Custom CustomField
+}
+
+var benchSmallValues = url.Values{
+	"author_id": {"123"},
+	"page":      {"2"},
+	"limit":     {"100"},
+}
+
+var benchMediumValues = url.Values{
+	"author_id":      {"123"},
+	"title":          {"go"},
+	"status":         {"draft", "published"},
+	"tags[]":         {"go", "sql"},
+	"created_at_gte": {"2020-01-01T00:00:00Z"},
+	"created_at_lt":  {"2021-01-01T00:00:00Z"},
+	"price_gte":      {"10"},
+	"price_lte":      {"100.5"},
+	"published":      {"true"},
+	"ids":            {"1", "2", "3"},
+}
+
+var benchLargeValues = func() url.Values {
+	values := url.Values{
+		"author[foo]":   {"foo"},
+		"author[bar]":   {"bar"},
+		"author[hello]": {"world"},
+
+		"field":     {"one"},
+		"field_neq": {"two"},
+		"field_lt":  {"1"},
+		"field_lte": {"2"},
+		"field_gt":  {"3"},
+		"field_gte": {"4"},
+
+		"multi":     {"one", "two"},
+		"multi_neq": {"3", "4"},
+
+		"map[foo]":   {"bar"},
+		"map[hello]": {"world"},
+
+		"custom": {"custom"},
+	}
+	for k, v := range benchMediumValues {
+		values[k] = v
+	}
+	return values
+}()
+
+func BenchmarkUnmarshalSmall(b *testing.B) {
+	benchmarkUnmarshal(b, benchSmallValues, func() interface{} {
+		return new(BenchSmallFilter)
+	})
+}
+
+func BenchmarkUnmarshalMedium(b *testing.B) {
+	benchmarkUnmarshal(b, benchMediumValues, func() interface{} {
+		return new(BenchMediumFilter)
+	})
+}
+
+func BenchmarkUnmarshalLarge(b *testing.B) {
+	benchmarkUnmarshal(b, benchLargeValues, func() interface{} {
+		return new(BenchLargeFilter)
+	})
+}
+
+func BenchmarkUnmarshalIgnoreCase(b *testing.B) {
+	values := make(url.Values, len(benchLargeValues))
+	for k, v := range benchLargeValues {
+		values[strings.ToUpper(k)] = v
+	}
+
+	d := &urlstruct.Decoder{IgnoreCase: true}
+	benchmarkDecoder(b, d, values, func() interface{} {
+		return new(BenchLargeFilter)
+	})
+}
+
+func benchmarkUnmarshal(b *testing.B, values url.Values, newFilter func() interface{}) {
+	benchmarkDecoder(b, new(urlstruct.Decoder), values, newFilter)
+}
+
+func benchmarkDecoder(
+	b *testing.B, d *urlstruct.Decoder, values url.Values, newFilter func() interface{},
+) {
+	ctx := context.Background()
+	filter := newFilter()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := d.Unmarshal(ctx, values, filter); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package urlstruct
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+// ParseBoolWords parses the strconv.ParseBool values and the words yes, no,
+// y, n, on and off in any case.
+func ParseBoolWords(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	if f, err := strconv.ParseBool(s); err == nil {
+		return f, nil
+	}
+	return false, &strconv.NumError{Func: "ParseBool", Num: s, Err: strconv.ErrSyntax}
+}
+
+var defaultBoolParser = flagParser(strconv.ParseBool)
+
+// boolParser returns the parser of bool values. Empty values are true,
+// so a param without a value like ?archived works as a flag.
+func (d *Decoder) boolParser() func(string) (bool, error) {
+	if d.ParseBool != nil {
+		return flagParser(d.ParseBool)
+	}
+	return defaultBoolParser
+}
+
+func flagParser(parse func(string) (bool, error)) func(string) (bool, error) {
+	return func(s string) (bool, error) {
+		if s == "" {
+			return true, nil
+		}
+		return parse(s)
+	}
+}
+
+// isFlag reports whether the type is decoded as a flag without values.
+func isFlag(typ reflect.Type, tag *tagparser.Tag) bool {
+	for {
+		elem, ok := nullable(typ)
+		if !ok {
+			break
+		}
+		typ = elem
+	}
+	return typ.Kind() == reflect.Bool && !hasUnmarshaler(typ, tag)
+}
+
+func boolScanner(parse func(string) (bool, error)) scannerFunc {
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		f, err := parse(values[0])
+		if err != nil {
+			return err
+		}
+		v.SetBool(f)
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package urlstruct_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type FlagFilter struct {
+	Archived bool
+	Deleted  *bool
+	Hidden   sql.NullBool
+	Pinned   *bool
+	Flags    []bool
+}
+
+var _ = Describe("Bools", func() {
+	ctx := context.TODO()
+
+	It("decodes params without a value as true", func() {
+		values, err := url.ParseQuery("archived&deleted&hidden&flags&flags=false")
+		Expect(err).NotTo(HaveOccurred())
+
+		f := new(FlagFilter)
+		err = urlstruct.Unmarshal(ctx, values, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Archived).To(BeTrue())
+		Expect(f.Deleted).To(Equal(newBool(true)))
+		Expect(f.Hidden).To(Equal(sql.NullBool{Bool: true, Valid: true}))
+		Expect(f.Pinned).To(BeNil())
+		Expect(f.Flags).To(Equal([]bool{true, false}))
+
+		var flag bool
+		Expect(urlstruct.Scan(ctx, &flag, []string{""})).To(Succeed())
+		Expect(flag).To(BeTrue())
+		Expect(urlstruct.Values(values).MaybeBool("archived")).To(BeTrue())
+	})
+
+	It("decodes *bool", func() {
+		f := new(FlagFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"deleted": {"false"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Deleted).To(Equal(newBool(false)))
+
+		f.Pinned = newBool(true)
+		err = urlstruct.Unmarshal(ctx, url.Values{"pinned": {"x"}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "pinned": ` +
+			`strconv.ParseBool: parsing "x": invalid syntax`))
+		Expect(f.Pinned).To(Equal(newBool(true)))
+	})
+
+	It("accepts words with ParseBoolWords", func() {
+		d := &urlstruct.Decoder{ParseBool: urlstruct.ParseBoolWords}
+
+		f := new(FlagFilter)
+		err := d.Unmarshal(ctx, url.Values{
+			"archived": {"yes"},
+			"deleted":  {"OFF"},
+			"hidden":   {"on"},
+			"pinned":   {"1"},
+			"flags":    {"Y", "n", "no", "0", "true"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Archived).To(BeTrue())
+		Expect(f.Deleted).To(Equal(newBool(false)))
+		Expect(f.Hidden).To(Equal(sql.NullBool{Bool: true, Valid: true}))
+		Expect(f.Pinned).To(Equal(newBool(true)))
+		Expect(f.Flags).To(Equal([]bool{true, false, false, false, true}))
+
+		err = d.Unmarshal(ctx, url.Values{"hidden": {"maybe"}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "hidden": ` +
+			`strconv.ParseBool: parsing "maybe": invalid syntax`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"archived": {"yes"}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "archived": ` +
+			`strconv.ParseBool: parsing "yes": invalid syntax`))
+	})
+
+	It("uses a custom ParseBool", func() {
+		d := &urlstruct.Decoder{
+			ParseBool: func(s string) (bool, error) {
+				return s == "ja", nil
+			},
+		}
+
+		f := new(FlagFilter)
+		err := d.Unmarshal(ctx, url.Values{
+			"archived": {"ja"},
+			"flags":    {"nein", ""},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Archived).To(BeTrue())
+		Expect(f.Flags).To(Equal([]bool{false, true}))
+	})
+})
+
+func newBool(f bool) *bool {
+	return &f
+}
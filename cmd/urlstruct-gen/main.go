@@ -0,0 +1,60 @@
+// Command urlstruct-gen generates UnmarshalValues methods that decode
+// url.Values into structs without reflection. Generated types implement
+// urlstruct.GeneratedUnmarshaler, so urlstruct.Unmarshal uses them instead
+// of the reflection-based decoder.
+//
+// Usage:
+//
+//	//go:generate urlstruct-gen -type=BookFilter,AuthorFilter
+//
+// The decoders honor the same tags and param names as urlstruct.Unmarshal.
+// Types that declare their own UnmarshalValues method are not supported.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-pg/urlstruct/internal/gen"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of struct type names; required")
+	output    = flag.String("output", "", "output file name; default <dir>/<type>_urlstruct.go")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: urlstruct-gen -type=T [-output=file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+	types := strings.Split(*typeNames, ",")
+
+	src, err := gen.Generate(dir, types)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	filename := *output
+	if filename == "" {
+		filename = gen.OutputFile(dir, types)
+	}
+	if err := os.WriteFile(filename, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
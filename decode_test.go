@@ -213,13 +213,10 @@ var _ = Describe("Decode", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(f.NullBool.Valid).To(BeTrue())
-		Expect(f.NullBool.Bool).To(BeZero())
+		Expect(f.NullBool.Bool).To(BeTrue())
 
-		Expect(f.NullInt64.Valid).To(BeTrue())
-		Expect(f.NullInt64.Int64).To(BeZero())
-
-		Expect(f.NullFloat64.Valid).To(BeTrue())
-		Expect(f.NullFloat64.Float64).To(BeZero())
+		Expect(f.NullInt64.Valid).To(BeFalse())
+		Expect(f.NullFloat64.Valid).To(BeFalse())
 
 		Expect(f.NullString.Valid).To(BeTrue())
 		Expect(f.NullString.String).To(BeZero())
@@ -233,4 +230,22 @@ var _ = Describe("Decode", func() {
 		Expect(f.SubFilter.Count).To(Equal(1))
 		Expect(f.Sub.Count).To(Equal(1))
 	})
+
+	It("returns the errors of name[key] params", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"bad[key]": {"value"}}, new(ParamErrorFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "bad": unknown param bad`))
+	})
 })
+
+type paramError string
+
+func (e paramError) Error() string {
+	return string(e)
+}
+
+// ParamErrorFilter rejects all params.
+type ParamErrorFilter struct{}
+
+func (f *ParamErrorFilter) UnmarshalParam(ctx context.Context, name string, values []string) error {
+	return paramError("unknown param " + name)
+}
@@ -0,0 +1,145 @@
+package urlstruct
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+var defaultDecoder = new(Decoder)
+
+// Decoder decodes URL query values into structs. The zero value is ready
+// to use and decodes values the same way as Unmarshal. Options must not
+// be changed after the first use. Decoders generated by urlstruct-gen
+// always use the default options, including the snake_case names.
+type Decoder struct {
+	// Strict makes the decoder reject structs with fields tagged with
+	// `urlstruct` that can't be decoded, for example, because their types
+	// are not supported. Untagged fields that can't be decoded, e.g. helper
+	// fields, and fields tagged with `urlstruct:"-"` are not reported;
+	// StructInfo.SkippedFields and Validate list them.
+	Strict bool
+
+	// Location is used to interpret times without an explicit UTC offset
+	// and the Unix timestamps. WithLocation overrides it per request.
+	// By default such times are in UTC and the timestamps in local time.
+	Location *time.Location
+
+	// RelativeTime enables relative time expressions like now-7d and
+	// today for all time.Time fields, not only for the fields with the
+	// `relative` tag option.
+	RelativeTime bool
+
+	// Naming converts the field names and the tag names to param names,
+	// e.g. CamelCase, KebabCase, ExactCase or a custom func. The default is
+	// SnakeCase. Aliases are used as is.
+	Naming func(name string) string
+
+	// IgnoreCase makes the decoder match param names and aliases
+	// case-insensitively, e.g. AUTHOR_ID decodes the author_id field.
+	// Exact matches take precedence.
+	IgnoreCase bool
+
+	// Repeat is the policy for repeated params of the fields that hold
+	// a single value. The default is RepeatFirst.
+	Repeat RepeatPolicy
+
+	// ParseBool parses the bool values, e.g. ParseBoolWords or a custom
+	// func. The default is strconv.ParseBool. Empty values are always
+	// true, so params like ?archived work as flags.
+	ParseBool func(s string) (bool, error)
+
+	// Null is the value that decodes as NULL into sql.Null* types, pointers
+	// and Optional. They also decode empty values as NULL unless they hold
+	// a string, a bool or a type that decodes itself, which gets the empty
+	// value. The default is "null".
+	Null string
+
+	// Limits bound the input a single Unmarshal processes.
+	Limits Limits
+
+	// DurationFormat is the syntax of time.Duration values. The default
+	// is the time.ParseDuration syntax.
+	DurationFormat DurationFormat
+
+	structs structInfoMap
+}
+
+// DescribeStruct returns the description of the struct. It returns
+// a ValidationError for structs with conflicting aliases and, in strict
+// mode, for structs with skipped tagged fields.
+func (d *Decoder) DescribeStruct(typ reflect.Type) (*StructInfo, error) {
+	sinfo, err := d.structs.describeStruct(d, typ)
+	if err != nil {
+		return nil, err
+	}
+	if sinfo.err != nil && sinfo.hasConflicts() {
+		return nil, sinfo.err
+	}
+	if d.Strict && sinfo.strictErr != nil {
+		return nil, sinfo.strictErr
+	}
+	return sinfo, nil
+}
+
+// Unmarshal unmarshals the URL query values into the struct. The strct must
+// be a non-nil pointer to a struct or an Unmarshaler; otherwise Unmarshal
+// returns an InvalidUnmarshalError.
+func (d *Decoder) Unmarshal(ctx context.Context, values url.Values, strct interface{}) error {
+	v := reflect.ValueOf(strct)
+	if isStructPtr(v) {
+		if u, ok := strct.(GeneratedUnmarshaler); ok && d.hasDefaultOptions() {
+			limits := d.Limits.resolve()
+			if err := limits.check(values); err != nil {
+				return err
+			}
+			return u.UnmarshalValues(withLimits(ctx, limits), values)
+		}
+
+		sinfo, err := d.DescribeStruct(v.Type())
+		if err != nil {
+			return err
+		}
+
+		if d.Repeat != RepeatFirst {
+			ctx = withRepeatPolicy(ctx, d.Repeat)
+		}
+
+		dec := structDecoder{
+			v:     v.Elem(),
+			sinfo: sinfo,
+		}
+		return dec.Decode(ctx, values)
+	}
+
+	if u, ok := strct.(Unmarshaler); ok && !isNilPtr(v) {
+		return u.UnmarshalValues(ctx, values)
+	}
+	return &InvalidUnmarshalError{Type: reflect.TypeOf(strct)}
+}
+
+// hasDefaultOptions reports whether the decoder decodes values the same
+// way as the decoders generated by urlstruct-gen. Limits are checked
+// before the generated decoders are called.
+func (d *Decoder) hasDefaultOptions() bool {
+	return !d.Strict &&
+		d.Location == nil &&
+		!d.RelativeTime &&
+		d.Naming == nil &&
+		!d.IgnoreCase &&
+		d.Repeat == RepeatFirst &&
+		d.ParseBool == nil &&
+		d.Null == "" &&
+		d.DurationFormat == DurationGo
+}
+
+// describeNested describes a nested struct field. Nested structs are
+// validated together with the parent struct.
+func (d *Decoder) describeNested(typ reflect.Type) *StructInfo {
+	sinfo, err := d.structs.describeStruct(d, typ)
+	if err != nil {
+		panic(err)
+	}
+	return sinfo
+}
@@ -0,0 +1,109 @@
+package urlstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-pg/urlstruct/internal/dominant"
+)
+
+func fieldKey(f *Field) (int, bool) {
+	return len(f.Index), f.tagged
+}
+
+func nestedKey(n *nestedStruct) (int, bool) {
+	return len(n.index), n.tagged
+}
+
+// resolveDuplicates keeps one field and one nested struct per name. Fields
+// of embedded structs are shadowed by the shallower ones silently. A field
+// without a tag name that loses to a tagged field at the same depth is
+// reported as skipped, and the names that remain ambiguous are reported
+// as conflicts and not decoded.
+func (s *StructInfo) resolveDuplicates(typ reflect.Type) {
+	groups := make(map[string][]*Field, len(s.fields))
+	for _, f := range s.fields {
+		groups[f.Name] = append(groups[f.Name], f)
+	}
+
+	fields := make([]*Field, 0, len(s.fields))
+	for _, f := range s.fields {
+		group := groups[f.Name]
+		if len(group) == 1 {
+			fields = append(fields, f)
+			continue
+		}
+
+		winner, ambiguous := dominant.Field(group, fieldKey)
+		if ambiguous != nil {
+			if f == group[0] {
+				s.conflicts = append(s.conflicts, fmt.Sprintf(
+					"name %q is used by fields %s", f.Name, joinPaths(typ, group, fieldIndex)))
+				delete(s.fieldMap, f.Name)
+			}
+			continue
+		}
+
+		if f == winner {
+			fields = append(fields, f)
+			s.fieldMap[f.Name] = f
+		} else if len(f.Index) == len(winner.Index) {
+			s.skipped = append(s.skipped, SkippedField{
+				Name:   f.goName,
+				Type:   f.Type,
+				Reason: fmt.Sprintf("duplicate name %q of field %s", f.Name, winner.goName),
+			})
+		}
+	}
+	s.fields = fields
+
+	nestedGroups := make(map[string][]*nestedStruct, len(s.nested))
+	for _, n := range s.nested {
+		nestedGroups[n.name] = append(nestedGroups[n.name], n)
+	}
+	for _, n := range s.nested {
+		group := nestedGroups[n.name]
+		if n != group[0] {
+			continue
+		}
+		winner, ambiguous := dominant.Field(group, nestedKey)
+		if ambiguous != nil {
+			s.conflicts = append(s.conflicts, fmt.Sprintf(
+				"name %q is used by nested structs %s", n.name, joinPaths(typ, group, nestedIndex)))
+			continue
+		}
+		if s.structs == nil {
+			s.structs = make(map[string]*nestedStruct)
+		}
+		s.structs[n.name] = winner
+	}
+	s.nested = nil
+}
+
+func fieldIndex(f *Field) []int {
+	return f.Index
+}
+
+func nestedIndex(n *nestedStruct) []int {
+	return n.index
+}
+
+// joinPaths returns the paths of the fields like Embedded.AuthorID.
+func joinPaths[T any](typ reflect.Type, fields []T, index func(T) []int) string {
+	paths := make([]string, len(fields))
+	for i, f := range fields {
+		var path []string
+		t := typ
+		for _, x := range index(f) {
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			sf := t.Field(x)
+			path = append(path, sf.Name)
+			t = sf.Type
+		}
+		paths[i] = strings.Join(path, ".")
+	}
+	return strings.Join(paths, " and ")
+}
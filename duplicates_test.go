@@ -0,0 +1,104 @@
+package urlstruct_test
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type AuthorFields struct {
+	AuthorID int
+	Page     DupPage
+}
+
+type DupPage struct {
+	Size int
+}
+
+type ShadowFilter struct {
+	AuthorFields
+	AuthorID int
+	Page     DupPage
+}
+
+type TaggedDupFilter struct {
+	Author   string `urlstruct:"author_id"`
+	AuthorID int
+}
+
+type OtherAuthorFields struct {
+	AuthorID int
+	Page     DupPage
+}
+
+type AmbiguousFilter struct {
+	AuthorFields
+	OtherAuthorFields
+}
+
+type DeepAuthorFields struct {
+	AuthorFields
+}
+
+type ResolvedFilter struct {
+	DeepAuthorFields
+	OtherAuthorFields
+}
+
+var _ = Describe("Duplicate names", func() {
+	ctx := context.TODO()
+
+	It("lets shallower fields shadow embedded ones", func() {
+		for i := 0; i < 10; i++ {
+			f := new(ShadowFilter)
+			err := urlstruct.Unmarshal(ctx, url.Values{
+				"author_id":  {"1"},
+				"page[size]": {"10"},
+			}, f)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.AuthorID).To(Equal(1))
+			Expect(f.AuthorFields.AuthorID).To(Equal(0))
+			Expect(f.Page.Size).To(Equal(10))
+			Expect(f.AuthorFields.Page.Size).To(Equal(0))
+		}
+
+		sinfo := urlstruct.DescribeStruct(reflect.TypeOf(ShadowFilter{}))
+		Expect(sinfo.Fields()).To(HaveLen(1))
+		Expect(sinfo.Field("author_id").Index).To(Equal([]int{1}))
+		Expect(sinfo.SkippedFields()).To(BeEmpty())
+
+		f := new(ResolvedFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"author_id": {"1"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.OtherAuthorFields.AuthorID).To(Equal(1))
+	})
+
+	It("prefers tagged fields at the same depth", func() {
+		f := new(TaggedDupFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"author_id": {"john"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Author).To(Equal("john"))
+		Expect(f.AuthorID).To(Equal(0))
+
+		err = urlstruct.Validate(reflect.TypeOf(TaggedDupFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.TaggedDupFilter: ` +
+			`field AuthorID has duplicate name "author_id" of field Author`))
+	})
+
+	It("rejects ambiguous names", func() {
+		_, err := new(urlstruct.Decoder).DescribeStruct(reflect.TypeOf(AmbiguousFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.AmbiguousFilter: ` +
+			`name "author_id" is used by fields ` +
+			`AuthorFields.AuthorID and OtherAuthorFields.AuthorID; ` +
+			`name "page" is used by nested structs ` +
+			`AuthorFields.Page and OtherAuthorFields.Page`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{}, new(AmbiguousFilter))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,340 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// DurationFormat is the syntax of time.Duration values. The format is
+// selected with Decoder.DurationFormat or per field with the `duration`
+// tag option, e.g. `urlstruct:",duration:iso8601"`.
+type DurationFormat int
+
+const (
+	// DurationGo is the time.ParseDuration syntax, e.g. 1h30m.
+	DurationGo DurationFormat = iota
+	// DurationISO8601 is the ISO 8601 syntax, e.g. P1DT12H or PT15M.
+	// Days are 24 hours long. Years and months are not supported,
+	// because their length varies.
+	DurationISO8601
+	// DurationAny accepts the Go syntax extended with d (24h) and w (7d)
+	// units, e.g. 7d or 1d12h, and the ISO 8601 syntax.
+	DurationAny
+)
+
+var durationFormats = map[string]DurationFormat{
+	"go":      DurationGo,
+	"iso8601": DurationISO8601,
+	"any":     DurationAny,
+}
+
+func (f DurationFormat) String() string {
+	for name, format := range durationFormats {
+		if format == f {
+			return name
+		}
+	}
+	return "DurationFormat(" + strconv.Itoa(int(f)) + ")"
+}
+
+// Parse parses the duration in the format.
+func (f DurationFormat) Parse(s string) (time.Duration, error) {
+	switch f {
+	case DurationISO8601:
+		return parseISO8601Duration(s)
+	case DurationAny:
+		if isISO8601Duration(s) {
+			return parseISO8601Duration(s)
+		}
+		return parseDayDuration(s)
+	default:
+		return time.ParseDuration(s)
+	}
+}
+
+// Format formats the duration in the format, so Parse returns the same
+// duration. DurationAny uses the Go syntax with the d unit, e.g. 1d12h.
+func (f DurationFormat) Format(d time.Duration) string {
+	switch f {
+	case DurationISO8601:
+		return formatISO8601Duration(d)
+	case DurationAny:
+		return formatDayDuration(d)
+	default:
+		return d.String()
+	}
+}
+
+func (d *Decoder) durationFormat(tag *tagparser.Tag) DurationFormat {
+	if tag != nil {
+		if format, ok := durationFormats[tag.Options["duration"]]; ok {
+			return format
+		}
+	}
+	return d.DurationFormat
+}
+
+func (d *Decoder) durationScanner(tag *tagparser.Tag) scannerFunc {
+	format := d.durationFormat(tag)
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		dur, err := format.Parse(values[0])
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(dur))
+		return nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func isISO8601Duration(s string) bool {
+	s = trimSign(s)
+	return s != "" && s[0] == 'P'
+}
+
+func trimSign(s string) string {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		return s[1:]
+	}
+	return s
+}
+
+func parseISO8601Duration(s string) (time.Duration, error) {
+	orig := s
+	neg := strings.HasPrefix(s, "-")
+	s = trimSign(s)
+
+	if len(s) < 2 || s[0] != 'P' {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+	}
+	s = s[1:]
+
+	var total uint64
+	var inTime bool
+	for s != "" {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		i := strings.IndexFunc(s, func(r rune) bool {
+			return r >= 'A' && r <= 'Z'
+		})
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+		}
+
+		var unit time.Duration
+		switch designator := s[i]; {
+		case !inTime && designator == 'W':
+			unit = week
+		case !inTime && designator == 'D':
+			unit = day
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, fmt.Errorf("ISO 8601 duration %q has years or months", orig)
+		default:
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+		}
+
+		// ISO 8601 allows both the comma and the dot as a decimal sign.
+		num := strings.Replace(s[:i], ",", ".", 1)
+		var ok bool
+		if total, ok = addDecimal(total, num, unit); !ok {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+		}
+		s = s[i+1:]
+	}
+
+	dur, ok := signedDuration(total, neg)
+	if !ok {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+	}
+	return dur, nil
+}
+
+// parseDayDuration parses the time.ParseDuration syntax extended with the
+// d and w units. Days and weeks must come before the other units.
+func parseDayDuration(s string) (time.Duration, error) {
+	if !strings.ContainsAny(s, "dw") {
+		return time.ParseDuration(s)
+	}
+
+	orig := s
+	neg := strings.HasPrefix(s, "-")
+	s = trimSign(s)
+
+	var total uint64
+	for s != "" {
+		i := strings.IndexAny(s, "dw")
+		if i < 0 {
+			dur, err := time.ParseDuration(s)
+			if err != nil || dur < 0 || total > maxMagnitude-uint64(dur) {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			total += uint64(dur)
+			break
+		}
+
+		unit := day
+		if s[i] == 'w' {
+			unit = week
+		}
+		var ok bool
+		if total, ok = addDecimal(total, s[:i], unit); !ok {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		s = s[i+1:]
+	}
+
+	dur, ok := signedDuration(total, neg)
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	return dur, nil
+}
+
+// maxMagnitude is the magnitude of math.MinInt64, the largest magnitude
+// of a duration.
+const maxMagnitude = 1 << 63
+
+// addDecimal adds a decimal number of units to the magnitude of a duration.
+// It reports false if the number is invalid or the result overflows.
+func addDecimal(total uint64, num string, unit time.Duration) (uint64, bool) {
+	intPart, fracPart, _ := strings.Cut(num, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, false
+	}
+
+	var d uint64
+	if intPart != "" {
+		n, err := strconv.ParseUint(intPart, 10, 64)
+		if err != nil || n > maxMagnitude/uint64(unit) {
+			return 0, false
+		}
+		d = n * uint64(unit)
+	}
+	if fracPart != "" {
+		f, _ := strconv.ParseFloat("0."+fracPart, 64)
+		frac := uint64(math.Round(f * float64(unit)))
+		if d > maxMagnitude-frac {
+			return 0, false
+		}
+		d += frac
+	}
+
+	if total > maxMagnitude-d {
+		return 0, false
+	}
+	return total + d, true
+}
+
+// signedDuration returns the duration with the magnitude. It reports false
+// if a positive duration overflows.
+func signedDuration(u uint64, neg bool) (time.Duration, bool) {
+	if neg {
+		return -time.Duration(u), true
+	}
+	if u > math.MaxInt64 {
+		return 0, false
+	}
+	return time.Duration(u), true
+}
+
+func isDigits(s string) bool {
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+func formatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	u := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		u = -u
+	}
+	b.WriteByte('P')
+
+	if days := u / uint64(day); days > 0 {
+		b.WriteString(strconv.FormatUint(days, 10))
+		b.WriteByte('D')
+		u %= uint64(day)
+	}
+	if u == 0 {
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	if hours := u / uint64(time.Hour); hours > 0 {
+		b.WriteString(strconv.FormatUint(hours, 10))
+		b.WriteByte('H')
+		u %= uint64(time.Hour)
+	}
+	if minutes := u / uint64(time.Minute); minutes > 0 {
+		b.WriteString(strconv.FormatUint(minutes, 10))
+		b.WriteByte('M')
+		u %= uint64(time.Minute)
+	}
+	if u > 0 {
+		b.WriteString(strconv.FormatUint(u/uint64(time.Second), 10))
+		if ns := u % uint64(time.Second); ns > 0 {
+			frac := fmt.Sprintf("%09d", ns)
+			b.WriteByte('.')
+			b.WriteString(strings.TrimRight(frac, "0"))
+		}
+		b.WriteByte('S')
+	}
+	return b.String()
+}
+
+func formatDayDuration(d time.Duration) string {
+	u := uint64(d)
+	var sign string
+	if d < 0 {
+		sign = "-"
+		u = -u
+	}
+
+	days := u / uint64(day)
+	if days == 0 {
+		return d.String()
+	}
+
+	s := sign + strconv.FormatUint(days, 10) + "d"
+	if rem := time.Duration(u % uint64(day)); rem > 0 {
+		s += rem.String()
+	}
+	return s
+}
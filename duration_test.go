@@ -0,0 +1,185 @@
+package urlstruct_test
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type DurationFilter struct {
+	Timeout  time.Duration
+	Interval time.Duration   `urlstruct:",duration:iso8601"`
+	Window   time.Duration   `urlstruct:",duration:any"`
+	Windows  []time.Duration `urlstruct:",duration:any"`
+}
+
+type InvalidDurationFilter struct {
+	Timeout time.Duration `urlstruct:",duration:cron"`
+}
+
+var _ = Describe("DurationFormat", func() {
+	It("parses ISO 8601 durations", func() {
+		tests := []struct {
+			s      string
+			wanted time.Duration
+		}{
+			{"P1D", 24 * time.Hour},
+			{"PT15M", 15 * time.Minute},
+			{"P1W", 7 * 24 * time.Hour},
+			{"P1DT12H", 36 * time.Hour},
+			{"PT1H30M15S", time.Hour + 30*time.Minute + 15*time.Second},
+			{"PT0.5S", 500 * time.Millisecond},
+			{"PT1,5H", 90 * time.Minute},
+			{"PT0S", 0},
+			{"-PT1M", -time.Minute},
+		}
+		for _, test := range tests {
+			d, err := urlstruct.DurationISO8601.Parse(test.s)
+			Expect(err).NotTo(HaveOccurred(), test.s)
+			Expect(d).To(Equal(test.wanted), test.s)
+		}
+	})
+
+	It("rejects invalid ISO 8601 durations", func() {
+		for _, s := range []string{"", "P", "PT", "1D", "P1H", "PT1D", "P1DT", "P-1D", "PT1.S2", "P1e3D", "1h"} {
+			_, err := urlstruct.DurationISO8601.Parse(s)
+			Expect(err).To(HaveOccurred(), s)
+		}
+
+		_, err := urlstruct.DurationISO8601.Parse("P1M")
+		Expect(err).To(MatchError(`ISO 8601 duration "P1M" has years or months`))
+
+		_, err = urlstruct.DurationISO8601.Parse("P999999999999D")
+		Expect(err).To(MatchError(`invalid ISO 8601 duration "P999999999999D"`))
+	})
+
+	It("parses days and weeks", func() {
+		tests := []struct {
+			s      string
+			wanted time.Duration
+		}{
+			{"7d", 7 * 24 * time.Hour},
+			{"1w", 7 * 24 * time.Hour},
+			{"1.5d", 36 * time.Hour},
+			{"1w2d3h", 9*24*time.Hour + 3*time.Hour},
+			{"-1d12h", -36 * time.Hour},
+			{"90m", 90 * time.Minute},
+			{"P1D", 24 * time.Hour},
+		}
+		for _, test := range tests {
+			d, err := urlstruct.DurationAny.Parse(test.s)
+			Expect(err).NotTo(HaveOccurred(), test.s)
+			Expect(d).To(Equal(test.wanted), test.s)
+		}
+
+		for _, s := range []string{"d", "1h2d", "1d-2h", "1x", "99999999999w"} {
+			_, err := urlstruct.DurationAny.Parse(s)
+			Expect(err).To(HaveOccurred(), s)
+		}
+
+		_, err := urlstruct.DurationGo.Parse("7d")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("parses the minimum duration", func() {
+		d, err := urlstruct.DurationISO8601.Parse("-PT2562047H47M16.854775808S")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(time.Duration(math.MinInt64)))
+
+		d, err = urlstruct.DurationAny.Parse("-106751d23h47m16.854775808s")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(time.Duration(math.MinInt64)))
+
+		_, err = urlstruct.DurationISO8601.Parse("PT2562047H47M16.854775808S")
+		Expect(err).To(HaveOccurred())
+		_, err = urlstruct.DurationAny.Parse("106751d23h47m16.854775808s")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("formats durations that parse back", func() {
+		durations := []time.Duration{
+			0,
+			time.Nanosecond,
+			1500 * time.Millisecond,
+			15 * time.Minute,
+			36 * time.Hour,
+			7*24*time.Hour + time.Second,
+			-90 * time.Minute,
+			math.MaxInt64,
+			-math.MaxInt64,
+			math.MinInt64,
+		}
+		formats := []urlstruct.DurationFormat{
+			urlstruct.DurationGo, urlstruct.DurationISO8601, urlstruct.DurationAny,
+		}
+		for _, format := range formats {
+			for _, d := range durations {
+				s := format.Format(d)
+				got, err := format.Parse(s)
+				Expect(err).NotTo(HaveOccurred(), s)
+				Expect(got).To(Equal(d), s)
+			}
+		}
+
+		Expect(urlstruct.DurationISO8601.Format(36 * time.Hour)).To(Equal("P1DT12H"))
+		Expect(urlstruct.DurationISO8601.Format(1500 * time.Millisecond)).To(Equal("PT1.5S"))
+		Expect(urlstruct.DurationISO8601.Format(0)).To(Equal("PT0S"))
+		Expect(urlstruct.DurationAny.Format(7 * 24 * time.Hour)).To(Equal("7d"))
+		Expect(urlstruct.DurationAny.Format(-36 * time.Hour)).To(Equal("-1d12h0m0s"))
+		Expect(urlstruct.DurationISO8601.String()).To(Equal("iso8601"))
+	})
+})
+
+var _ = Describe("Duration scanner", func() {
+	ctx := context.TODO()
+
+	It("uses the duration tag option", func() {
+		f := new(DurationFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"timeout":  {"1m"},
+			"interval": {"PT15M"},
+			"window":   {"7d"},
+			"windows":  {"1d", "P1W"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Timeout).To(Equal(time.Minute))
+		Expect(f.Interval).To(Equal(15 * time.Minute))
+		Expect(f.Window).To(Equal(7 * 24 * time.Hour))
+		Expect(f.Windows).To(Equal([]time.Duration{24 * time.Hour, 7 * 24 * time.Hour}))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"interval": {"15m"}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "interval": invalid ISO 8601 duration "15m"`))
+	})
+
+	It("uses the decoder option", func() {
+		d := &urlstruct.Decoder{DurationFormat: urlstruct.DurationISO8601}
+		f := new(DurationFilter)
+		err := d.Unmarshal(ctx, url.Values{
+			"timeout": {"P1D"},
+			"window":  {"2d"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Timeout).To(Equal(24 * time.Hour))
+		Expect(f.Window).To(Equal(48 * time.Hour))
+	})
+
+	It("rejects unknown formats", func() {
+		err := urlstruct.Validate(reflect.TypeOf(InvalidDurationFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.InvalidDurationFilter: ` +
+			`field Timeout has invalid duration format "cron"`))
+	})
+
+	It("accepts any format in Values", func() {
+		values := urlstruct.Values{"a": {"7d"}, "b": {"PT1M"}, "c": {"1h"}}
+		Expect(values.MaybeDuration("a")).To(Equal(7 * 24 * time.Hour))
+		Expect(values.MaybeDuration("b")).To(Equal(time.Minute))
+		Expect(values.MaybeDuration("c")).To(Equal(time.Hour))
+	})
+})
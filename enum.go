@@ -0,0 +1,175 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+// Enum is implemented by string types that accept only the declared
+// values, for example, a Status type with constants. The same can be
+// declared per field with the `enum` tag option, e.g.
+// `urlstruct:",enum:active|inactive|removed=inactive"`, where removed is
+// an alias for inactive. The `ignorecase` tag option makes the decoder
+// match values case-insensitively. Decoded values are always canonical.
+type Enum interface {
+	EnumValues() []string
+}
+
+// EnumAliaser is implemented by Enum types that accept alternative
+// spellings of the values. EnumAliases maps the aliases to the values.
+type EnumAliaser interface {
+	Enum
+	EnumAliases() map[string]string
+}
+
+var enumType = reflect.TypeOf((*Enum)(nil)).Elem()
+
+// EnumError is returned for values that are not in the declared set.
+type EnumError struct {
+	Value   string
+	Allowed []string
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("value %q is not one of %s", e.Value, strings.Join(e.Allowed, ", "))
+}
+
+type enum struct {
+	values     []string
+	lookup     map[string]string
+	ignoreCase bool
+}
+
+// fieldEnum returns the enum of the field or of the slice or array elements.
+func fieldEnum(typ reflect.Type, tag *tagparser.Tag) (*enum, error) {
+	if !hasUnmarshaler(typ, tag) && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	return enumFor(typ, tag)
+}
+
+// enumFor returns the enum declared by the tag or the type. It returns nil
+// if the values are not restricted.
+func enumFor(typ reflect.Type, tag *tagparser.Tag) (*enum, error) {
+	var values []string
+	var aliases map[string]string
+
+	if spec, ok := tagOption(tag, "enum"); ok {
+		values, aliases = parseEnumTag(spec)
+	} else if reflect.PtrTo(typ).Implements(enumType) {
+		e := reflect.New(typ).Interface().(Enum)
+		values = e.EnumValues()
+		if a, ok := e.(EnumAliaser); ok {
+			aliases = a.EnumAliases()
+		}
+	} else {
+		return nil, nil
+	}
+
+	if typ.Kind() != reflect.String {
+		return nil, fmt.Errorf("enum values for non-string type %s", typ)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty enum")
+	}
+	return newEnum(values, aliases, hasOption(tag, "ignorecase"))
+}
+
+// parseEnumTag parses values like `a|b|c=b`, where c is an alias for b.
+func parseEnumTag(spec string) (values []string, aliases map[string]string) {
+	for _, s := range strings.Split(strings.Trim(spec, "'"), "|") {
+		if alias, value, ok := strings.Cut(s, "="); ok {
+			if aliases == nil {
+				aliases = make(map[string]string)
+			}
+			aliases[alias] = value
+			continue
+		}
+		values = append(values, s)
+	}
+	return values, aliases
+}
+
+func newEnum(values []string, aliases map[string]string, ignoreCase bool) (*enum, error) {
+	e := &enum{
+		values:     values,
+		lookup:     make(map[string]string, len(values)+len(aliases)),
+		ignoreCase: ignoreCase,
+	}
+
+	add := func(s, value string) error {
+		key := e.key(s)
+		if prev, ok := e.lookup[key]; ok && prev != value {
+			return fmt.Errorf("enum value %q is ambiguous", s)
+		}
+		e.lookup[key] = value
+		return nil
+	}
+
+	for _, value := range values {
+		if err := add(value, value); err != nil {
+			return nil, err
+		}
+	}
+	for alias, value := range aliases {
+		if _, ok := e.lookup[e.key(value)]; !ok {
+			return nil, fmt.Errorf("enum alias %q refers to unknown value %q", alias, value)
+		}
+		if err := add(alias, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+func (e *enum) key(s string) string {
+	if e.ignoreCase {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+func (e *enum) parse(s string) (string, error) {
+	if value, ok := e.lookup[e.key(s)]; ok {
+		return value, nil
+	}
+	return "", &EnumError{
+		Value:   s,
+		Allowed: e.values,
+	}
+}
+
+func (e *enum) scan(ctx context.Context, v reflect.Value, values []string) error {
+	s, err := e.parse(values[0])
+	if err != nil {
+		return err
+	}
+	v.SetString(s)
+	return nil
+}
+
+func (e *enum) scanSlice(ctx context.Context, v reflect.Value, values []string) error {
+	nn := reflect.MakeSlice(v.Type(), len(values), len(values))
+	for i, s := range values {
+		s, err := e.parse(s)
+		if err != nil {
+			return err
+		}
+		nn.Index(i).SetString(s)
+	}
+	v.Set(nn)
+	return nil
+}
+
+func tagOption(tag *tagparser.Tag, name string) (string, bool) {
+	if tag == nil {
+		return "", false
+	}
+	s, ok := tag.Options[name]
+	return s, ok
+}
@@ -0,0 +1,117 @@
+package urlstruct_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type Color string
+
+const (
+	Red   Color = "red"
+	Green Color = "green"
+)
+
+func (Color) EnumValues() []string {
+	return []string{string(Red), string(Green)}
+}
+
+func (Color) EnumAliases() map[string]string {
+	return map[string]string{"verde": string(Green)}
+}
+
+type EnumFilter struct {
+	Color    Color
+	Colors   []Color
+	AnyColor Color    `urlstruct:",ignorecase"`
+	Sort     string   `urlstruct:",enum:asc|desc|ascending=asc|descending=desc"`
+	Sorts    []string `urlstruct:",enum:asc|desc,ignorecase"`
+	Pair     [2]Color
+}
+
+type InvalidEnumFilter struct {
+	Count   int    `urlstruct:",enum:1|2"`
+	Alias   string `urlstruct:",enum:a|b|c=d"`
+	Similar string `urlstruct:",enum:a|A,ignorecase"`
+}
+
+var _ = Describe("Enum", func() {
+	ctx := context.TODO()
+
+	It("decodes declared values and aliases", func() {
+		f := new(EnumFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"color":     {"red"},
+			"colors":    {"green", "verde"},
+			"any_color": {"GREEN"},
+			"sort":      {"descending"},
+			"sorts":     {"ASC", "Desc"},
+			"pair":      {"red", "verde"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Color).To(Equal(Red))
+		Expect(f.Colors).To(Equal([]Color{Green, Green}))
+		Expect(f.AnyColor).To(Equal(Green))
+		Expect(f.Sort).To(Equal("desc"))
+		Expect(f.Sorts).To(Equal([]string{"asc", "desc"}))
+		Expect(f.Pair).To(Equal([2]Color{Red, Green}))
+	})
+
+	It("rejects unknown values", func() {
+		tests := []struct {
+			values url.Values
+			err    string
+		}{
+			{url.Values{"color": {"blue"}}, `urlstruct: can't decode "color": value "blue" is not one of red, green`},
+			{url.Values{"color": {"RED"}}, `urlstruct: can't decode "color": value "RED" is not one of red, green`},
+			{url.Values{"colors": {"red", ""}}, `urlstruct: can't decode "colors": value "" is not one of red, green`},
+			{url.Values{"sort": {"up"}}, `urlstruct: can't decode "sort": value "up" is not one of asc, desc`},
+			{url.Values{"pair": {"red", "blue"}}, `urlstruct: can't decode "pair": value "blue" is not one of red, green`},
+		}
+		for _, test := range tests {
+			err := urlstruct.Unmarshal(ctx, test.values, new(EnumFilter))
+			Expect(err).To(MatchError(test.err))
+
+			var enumErr *urlstruct.EnumError
+			Expect(errors.As(err, &enumErr)).To(BeTrue())
+		}
+	})
+
+	It("checks Enum types in Scan", func() {
+		var c Color
+		Expect(urlstruct.Scan(ctx, &c, []string{"verde"})).To(Succeed())
+		Expect(c).To(Equal(Green))
+		Expect(urlstruct.Scan(ctx, &c, []string{"blue"})).NotTo(Succeed())
+	})
+
+	It("exposes the allowed values", func() {
+		sinfo := urlstruct.DescribeStruct(reflect.TypeOf(EnumFilter{}))
+		Expect(sinfo.Field("color").EnumValues()).To(Equal([]string{"red", "green"}))
+		Expect(sinfo.Field("colors").EnumValues()).To(Equal([]string{"red", "green"}))
+		Expect(sinfo.Field("sort").EnumValues()).To(Equal([]string{"asc", "desc"}))
+
+		var names []string
+		for _, f := range sinfo.Fields() {
+			names = append(names, f.Name)
+		}
+		Expect(names).To(Equal([]string{"color", "colors", "any_color", "sort", "sorts", "pair"}))
+
+		sinfo = urlstruct.DescribeStruct(reflect.TypeOf(SliceFilter{}))
+		Expect(sinfo.Field("statuses").EnumValues()).To(BeNil())
+	})
+
+	It("rejects invalid enum declarations", func() {
+		err := urlstruct.Validate(reflect.TypeOf(InvalidEnumFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.InvalidEnumFilter: ` +
+			`field Count has enum values for non-string type int; ` +
+			`field Alias has enum alias "c" refers to unknown value "d"; ` +
+			`field Similar has enum value "A" is ambiguous`))
+	})
+})
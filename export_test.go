@@ -0,0 +1,24 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+var (
+	ParseTime = parseTime
+	MapKey    = mapKey
+)
+
+// ScanField decodes the values into v with the scanner Unmarshal uses
+// for a field of the type with the tag.
+func ScanField(ctx context.Context, v reflect.Value, tag string, values []string) error {
+	scan := defaultDecoder.fieldScanner(v.Type(), tagparser.Parse(tag))
+	if scan == nil {
+		return fmt.Errorf("unsupported %s", v.Type())
+	}
+	return scan(ctx, v, values)
+}
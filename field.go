@@ -1,31 +1,152 @@
 package urlstruct
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 
 	"github.com/vmihailenco/tagparser"
 )
 
 type Field struct {
-	Type  reflect.Type
-	Name  string
-	Index []int
-	Tag   *tagparser.Tag
+	Type    reflect.Type
+	Name    string
+	Aliases []string
+	Index   []int
+	Tag     *tagparser.Tag
 
-	noDecode  bool
-	scanValue scannerFunc
+	goName   string
+	tagged   bool
+	noDecode bool
+	keyed    bool
+	decode   methodFunc
+	set      setterFunc
+	enum     *enum
 }
 
-func (f *Field) init() {
+func (f *Field) init(d *Decoder) {
 	_, f.noDecode = f.Tag.Options["nodecode"]
+	f.set = d.plainSetter(f)
+	if f.set == nil {
+		scan := d.repeatScanner(f.Type, f.Tag, d.fieldScanner(f.Type, f.Tag))
+		f.set = newSetter(f.Index, f.decode, scan)
+	}
+	f.enum, _ = fieldEnum(f.Type, f.Tag)
+	// Maps are decoded only from the name[key] params.
+	f.keyed = f.Type.Kind() == reflect.Map && f.decode == nil && !hasUnmarshaler(f.Type, f.Tag)
+}
+
+// setterFunc decodes the values into the field of the struct.
+type setterFunc func(ctx context.Context, strct reflect.Value, values []string) error
+
+// plainSetter returns the setter for the string and signed integer fields
+// of the struct itself that have no tag options. It assigns the first value
+// without going through the scanners.
+func (d *Decoder) plainSetter(f *Field) setterFunc {
+	if f.decode != nil ||
+		len(f.Index) != 1 ||
+		len(f.Tag.Options) > 0 ||
+		d.Repeat != RepeatFirst ||
+		hasUnmarshaler(f.Type, nil) {
+		return nil
+	}
 
-	if f.Type.Kind() == reflect.Slice {
-		f.scanValue = sliceScanner(f.Type)
-	} else {
-		f.scanValue = scanner(f.Type)
+	i := f.Index[0]
+	typ := f.Type
+	switch typ.Kind() {
+	case reflect.String:
+		if e, _ := enumFor(typ, nil); e != nil {
+			return nil
+		}
+		return func(ctx context.Context, strct reflect.Value, values []string) error {
+			if len(values) > 0 {
+				strct.Field(i).SetString(values[0])
+			}
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ == durationType {
+			return nil
+		}
+		return func(ctx context.Context, strct reflect.Value, values []string) error {
+			if len(values) == 0 {
+				return nil
+			}
+			n, err := parseInt(values[0], typ, false)
+			if err != nil {
+				return err
+			}
+			strct.Field(i).SetInt(n)
+			return nil
+		}
 	}
+	return nil
+}
+
+// newSetter returns the setter that calls the decoder method or, if there
+// is none, locates the field and scans the values into it. The setter is
+// built once per field, so decoding a param only calls it.
+func newSetter(index []int, decode methodFunc, scan scannerFunc) setterFunc {
+	if decode != nil {
+		return setterFunc(decode)
+	}
+	if scan == nil {
+		return nil
+	}
+	if len(index) == 1 {
+		i := index[0]
+		return func(ctx context.Context, strct reflect.Value, values []string) error {
+			return scan(ctx, strct.Field(i), values)
+		}
+	}
+	return func(ctx context.Context, strct reflect.Value, values []string) error {
+		return scan(ctx, fieldByIndex(strct, index), values)
+	}
+}
+
+// EnumValues returns the values allowed by the Enum type or the `enum` tag
+// option. It returns nil if the values are not restricted.
+func (f *Field) EnumValues() []string {
+	if f.enum == nil {
+		return nil
+	}
+	return f.enum.values
 }
 
 func (f *Field) Value(strct reflect.Value) reflect.Value {
 	return strct.FieldByIndex(f.Index)
 }
+
+// methodFunc calls the method named by the `decoder` tag option
+// on the struct that contains the field.
+type methodFunc func(ctx context.Context, strct reflect.Value, values []string) error
+
+// decoderMethod returns the func that calls the method of the struct that
+// declares the field. The method must have the signature
+// func(ctx context.Context, values []string) error.
+func decoderMethod(typ reflect.Type, baseIndex []int, name string) (methodFunc, error) {
+	meth, ok := reflect.PtrTo(typ).MethodByName(name)
+	if !ok ||
+		meth.Type.NumIn() != 3 ||
+		meth.Type.NumOut() != 1 ||
+		meth.Type.In(1) != contextType ||
+		meth.Type.In(2) != stringSliceType ||
+		meth.Type.Out(0) != errorType {
+		return nil, fmt.Errorf("invalid decoder %q", name)
+	}
+
+	return func(ctx context.Context, strct reflect.Value, values []string) error {
+		v := strct
+		if len(baseIndex) > 0 {
+			v = fieldByIndex(strct, baseIndex)
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		fn := v.Addr().Method(meth.Index).Interface().(func(context.Context, []string) error)
+		return fn(ctx, values)
+	}, nil
+}
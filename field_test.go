@@ -0,0 +1,187 @@
+package urlstruct_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type HookEmbedded struct {
+	Region string `urlstruct:",decoder:DecodeRegion"`
+}
+
+func (e *HookEmbedded) DecodeRegion(ctx context.Context, values []string) error {
+	e.Region = strings.ToUpper(values[0])
+	return nil
+}
+
+type HookSub struct {
+	Count int
+}
+
+var _ urlstruct.FieldDecoder = (*HookSub)(nil)
+
+func (s *HookSub) DecodeURLField(name string, values []string) (bool, error) {
+	if name == "count" && values[0] == "many" {
+		s.Count = 100
+		return true, nil
+	}
+	return false, nil
+}
+
+type HookFilter struct {
+	HookEmbedded
+	Sub HookSub
+
+	Sort   []string `urlstruct:",decoder:DecodeSort"`
+	Matrix [][]int  `urlstruct:",decoder:DecodeMatrix"`
+	Page   int
+	Limit  int
+}
+
+var _ urlstruct.FieldDecoder = (*HookFilter)(nil)
+
+func (f *HookFilter) DecodeURLField(name string, values []string) (bool, error) {
+	switch name {
+	case "page":
+		if values[0] == "last" {
+			f.Page = -1
+			return true, nil
+		}
+	case "sort":
+		if values[0] == "default" {
+			f.Sort = []string{"id"}
+			return true, nil
+		}
+	case "limit":
+		return false, errors.New("limit is fixed")
+	}
+	return false, nil
+}
+
+func (f *HookFilter) DecodeSort(ctx context.Context, values []string) error {
+	f.Sort = nil
+	for _, s := range values {
+		if s == "" {
+			return errors.New("empty sort")
+		}
+		f.Sort = append(f.Sort, strings.Split(s, ",")...)
+	}
+	return nil
+}
+
+func (f *HookFilter) DecodeMatrix(ctx context.Context, values []string) error {
+	f.Matrix = make([][]int, len(values))
+	for i, row := range values {
+		for _, s := range strings.Split(row, ",") {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return err
+			}
+			f.Matrix[i] = append(f.Matrix[i], n)
+		}
+	}
+	return nil
+}
+
+type TagsInner struct {
+	Tags []string `urlstruct:",decoder:ParseTags"`
+}
+
+func (in *TagsInner) ParseTags(ctx context.Context, values []string) error {
+	in.Tags = strings.Split(values[0], ",")
+	return nil
+}
+
+type TagsMid struct {
+	*TagsInner
+}
+
+type TagsOuter struct {
+	*TagsMid
+}
+
+type InvalidHookFilter struct {
+	Missing string `urlstruct:",decoder:Missing"`
+	Invalid string `urlstruct:",decoder:DecodeInvalid"`
+}
+
+func (f *InvalidHookFilter) DecodeInvalid(values []string) error {
+	return nil
+}
+
+var _ = Describe("Field hooks", func() {
+	ctx := context.TODO()
+
+	It("decodes the fields with the decoder methods", func() {
+		f := new(HookFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"region": {"eu"},
+			"sort":   {"name,-id", "age"},
+			"matrix": {"1,2", "3"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Region).To(Equal("EU"))
+		Expect(f.Sort).To(Equal([]string{"name", "-id", "age"}))
+		Expect(f.Matrix).To(Equal([][]int{{1, 2}, {3}}))
+	})
+
+	It("consults DecodeURLField first", func() {
+		f := new(HookFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"page":       {"last"},
+			"sort":       {"default"},
+			"sub[count]": {"many"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Page).To(Equal(-1))
+		Expect(f.Sort).To(Equal([]string{"id"}))
+		Expect(f.Sub.Count).To(Equal(100))
+
+		f = new(HookFilter)
+		err = urlstruct.Unmarshal(ctx, url.Values{
+			"page":       {"2"},
+			"sub[count]": {"3"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Page).To(Equal(2))
+		Expect(f.Sub.Count).To(Equal(3))
+	})
+
+	It("allocates the nil embedded pointers", func() {
+		f := new(TagsOuter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"tags": {"a,b"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Tags).To(Equal([]string{"a", "b"}))
+	})
+
+	It("returns the errors of the hooks", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"sort": {""}}, new(HookFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "sort": empty sort`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"limit": {"10"}}, new(HookFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "limit": limit is fixed`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"matrix": {"1,x"}}, new(HookFilter))
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, strconv.ErrSyntax)).To(BeTrue())
+	})
+
+	It("skips the fields with invalid decoders", func() {
+		sinfo := urlstruct.DescribeStruct(reflect.TypeOf(InvalidHookFilter{}))
+		Expect(sinfo.Fields()).To(BeEmpty())
+
+		skipped := sinfo.SkippedFields()
+		Expect(skipped).To(HaveLen(2))
+		Expect(skipped[0].Reason).To(Equal(`invalid decoder "Missing"`))
+		Expect(skipped[1].Reason).To(Equal(`invalid decoder "DecodeInvalid"`))
+	})
+})
@@ -0,0 +1,408 @@
+package urlstruct_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type FuzzFilter struct {
+	urlstruct.Pager
+	Sub  SubFilter
+	SMap StructMap
+
+	Bool    bool
+	Int     int
+	Int8    int8
+	Uint    uint
+	Uint16  uint16 `urlstruct:",clamp"`
+	Float32 float32
+	Float64 float64
+	String  string `urlstruct:",repeat:join"`
+
+	BoolPtr   *bool
+	IntPtr    *int
+	StringPtr *string
+	TimePtr   *time.Time
+
+	Bools   []bool
+	Ints    []int64
+	Uints   []uint32
+	Floats  []float64
+	Strings []string
+	Array   [2]int
+	Bytes   []byte  `urlstruct:",base64"`
+	Hash    [4]byte `urlstruct:",base64"`
+
+	Time     time.Time
+	Millis   time.Time `urlstruct:",unit:ms"`
+	Layout   time.Time `urlstruct:",layout:'2006-01-02 15:04'"`
+	Relative time.Time `urlstruct:",relative"`
+	Times    []time.Time
+	Period   urlstruct.TimeRange `urlstruct:",relative"`
+	Day      urlstruct.Date
+
+	Duration  time.Duration
+	ISO       time.Duration `urlstruct:",duration:iso8601"`
+	Durations []time.Duration
+
+	Price urlstruct.Range[float64]
+	Count urlstruct.Range[int]
+
+	Color  Color
+	Colors []Color
+	Sort   string `urlstruct:",enum:asc|desc|ascending=asc,ignorecase"`
+
+	NullBool    sql.NullBool
+	NullInt64   sql.NullInt64
+	NullFloat64 sql.NullFloat64
+	NullString  sql.NullString
+	NullInts    []sql.NullInt64
+
+	Optional  urlstruct.Optional[int]
+	Optionals urlstruct.Optional[[]string]
+
+	Map    map[string]string
+	Custom CustomField
+	Uuid   uuid.UUID
+	Uuids  []uuid.UUID
+}
+
+var fuzzDecoders = []*urlstruct.Decoder{
+	new(urlstruct.Decoder),
+	{
+		Location:     time.UTC,
+		RelativeTime: true,
+		IgnoreCase:   true,
+		Repeat:       urlstruct.RepeatError,
+		ParseBool:    urlstruct.ParseBoolWords,
+		Limits:       urlstruct.Limits{MaxValueLen: 1 << 10},
+	},
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	f.Add("page=2&limit=10&bool=t&int=-1&int8=300&uint=1&uint16=-5&float32=1e40")
+	f.Add("string=a&string=b&bool_ptr=1&int_ptr=2&string_ptr=&time_ptr=0")
+	f.Add("bools=t&ints=1&uints=2&floats=NaN&strings=a&array=1&array=2&bytes=aGk&hash=AQIDBA==")
+	f.Add("time=2024-03-01&millis=1700000000000&layout=2024-03-01+10:00&relative=now-7d")
+	f.Add("times=20240301T100000&period=now-1w..today&day=2024-02-29")
+	f.Add("duration=1h&iso=P1DT2H&durations=-1.5s&durations=PT1M")
+	f.Add("price=[1.5,2)&count=1..&count[gte]=1&price[lt]=3")
+	f.Add("color=red&colors=green&colors=blue&sort=ASCENDING")
+	f.Add("null_bool=&null_int64=1&null_float64=x&null_string=s&null_ints=1&null_ints=")
+	f.Add("int_ptr=null&string_ptr=&optional=null&optionals=a&optionals=null&time_ptr=")
+	f.Add("map[a]=1&map[b]=2&sub[count]=1&s_map[foo]=1&s_map[x]=2&custom=c")
+	f.Add("uuid=5f8c5c0e-1f3e-4c59-9c5b-3e1e2b1f6a11&uuids=&:page=1&limit[]=1&a[b][c]=1")
+
+	ctx := urlstruct.WithClock(context.Background(), func() time.Time {
+		return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	})
+	f.Fuzz(func(t *testing.T, query string) {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return
+		}
+		for _, d := range fuzzDecoders {
+			_ = d.Unmarshal(ctx, values, new(FuzzFilter))
+		}
+	})
+}
+
+func FuzzParseTime(f *testing.F) {
+	f.Add("1700000000")
+	f.Add("-62135596800")
+	f.Add("2024-03-01")
+	f.Add("2024-03-01T10:00:00")
+	f.Add("2024-03-01T10:00:00.123456789+05:30")
+	f.Add("20240301T100000")
+	f.Add("20240301T100000-07:00")
+	f.Add("2024-")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		tm, err := urlstruct.ParseTime(s)
+		if err != nil || !isFormattable(tm) {
+			return
+		}
+
+		formatted := tm.Format(time.RFC3339Nano)
+		got, err := urlstruct.ParseTime(formatted)
+		if err != nil {
+			t.Fatalf("ParseTime(%q) of %q failed: %s", formatted, s, err)
+		}
+		if !got.Equal(tm) {
+			t.Fatalf("ParseTime(%q) = %s, wanted %s", formatted, got, tm)
+		}
+	})
+}
+
+func FuzzMapKey(f *testing.F) {
+	f.Add("map[key]")
+	f.Add("map[]")
+	f.Add("[key]")
+	f.Add("a[b][c]")
+	f.Add("map[key")
+	f.Add("]")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		name, key, ok := urlstruct.MapKey(s)
+		if !ok {
+			return
+		}
+		if key == "" || strings.Contains(name, "[") {
+			t.Fatalf("MapKey(%q) = %q, %q", s, name, key)
+		}
+		if got := name + "[" + key + "]"; got != s {
+			t.Fatalf("MapKey(%q) = %q, %q, which is %q", s, name, key, got)
+		}
+	})
+}
+
+// fuzzScanTypes are the types passed to the scanners, together with
+// the tag of the field.
+var fuzzScanTypes = []struct {
+	typ reflect.Type
+	tag string
+}{
+	{reflect.TypeOf(false), ""},
+	{reflect.TypeOf(int8(0)), ""},
+	{reflect.TypeOf(int64(0)), ",clamp"},
+	{reflect.TypeOf(uint(0)), ""},
+	{reflect.TypeOf(uint8(0)), ",clamp"},
+	{reflect.TypeOf(float32(0)), ""},
+	{reflect.TypeOf(""), ""},
+	{reflect.TypeOf(""), ",enum:a|b|c=a,ignorecase"},
+	{reflect.TypeOf(Color("")), ""},
+	{reflect.TypeOf(time.Time{}), ""},
+	{reflect.TypeOf(time.Time{}), ",unit:us,relative"},
+	{reflect.TypeOf(time.Time{}), ",layout:Jan _2 15:04:05"},
+	{reflect.TypeOf(time.Duration(0)), ""},
+	{reflect.TypeOf(time.Duration(0)), ",duration:any"},
+	{reflect.TypeOf(urlstruct.TimeRange{}), ",relative"},
+	{reflect.TypeOf(urlstruct.Date{}), ""},
+	{reflect.TypeOf(urlstruct.Range[int8]{}), ""},
+	{reflect.TypeOf(urlstruct.Range[float32]{}), ""},
+	{reflect.TypeOf(sql.NullBool{}), ""},
+	{reflect.TypeOf(sql.NullInt64{}), ""},
+	{reflect.TypeOf(sql.NullFloat64{}), ""},
+	{reflect.TypeOf(sql.NullString{}), ""},
+	{reflect.TypeOf(sql.NullTime{}), ",relative"},
+	{reflect.TypeOf(sql.NullByte{}), ""},
+	{reflect.TypeOf(map[string]string{}), ""},
+	{reflect.TypeOf(map[Color]int{}), ",clamp"},
+	{reflect.TypeOf(map[uuid.UUID]*CustomField{}), ""},
+	{reflect.TypeOf(new(int)), ""},
+	{reflect.TypeOf(new(string)), ""},
+	{reflect.TypeOf(urlstruct.Optional[float64]{}), ""},
+	{reflect.TypeOf(urlstruct.Optional[[]int]{}), ""},
+	{reflect.TypeOf(CustomField{}), ""},
+	{reflect.TypeOf(&CustomField{}), ""},
+	{reflect.TypeOf(uuid.UUID{}), ""},
+	{reflect.TypeOf(Via{}), ""},
+	{reflect.TypeOf(BinaryVia{}), ""},
+	{reflect.TypeOf(Blob{}), ""},
+	{reflect.TypeOf(BinaryVia{}), ",base64"},
+	{reflect.TypeOf(Blob{}), ",base64"},
+	{reflect.TypeOf(Tags{}), ""},
+	{reflect.TypeOf([]bool{}), ""},
+	{reflect.TypeOf([]int16{}), ",clamp"},
+	{reflect.TypeOf([]uint64{}), ""},
+	{reflect.TypeOf([]float64{}), ""},
+	{reflect.TypeOf([]string{}), ""},
+	{reflect.TypeOf([]Color{}), ""},
+	{reflect.TypeOf([]time.Time{}), ",unit:ms"},
+	{reflect.TypeOf([]time.Duration{}), ",duration:iso8601"},
+	{reflect.TypeOf([]sql.NullString{}), ""},
+	{reflect.TypeOf([]CustomField{}), ""},
+	{reflect.TypeOf([]uuid.UUID{}), ""},
+	{reflect.TypeOf([]byte{}), ""},
+	{reflect.TypeOf([]byte{}), ",base64"},
+	{reflect.TypeOf([4]byte{}), ",base64"},
+	{reflect.TypeOf([3]int{}), ""},
+}
+
+// FuzzScan decodes the values separated by newlines with each scanner.
+func FuzzScan(f *testing.F) {
+	f.Add("1")
+	f.Add("-1\n2")
+	f.Add("")
+	f.Add("now-7d..now")
+	f.Add("2024-03-01..2024-03-02")
+	f.Add("(1,2]")
+	f.Add("P1DT1H\nPT0.5S")
+	f.Add("AQID\nAQIDBA")
+	f.Add("a\nB\nc")
+
+	ctx := context.Background()
+	f.Fuzz(func(t *testing.T, s string) {
+		values := strings.Split(s, "\n")
+		for _, test := range fuzzScanTypes {
+			v := reflect.New(test.typ).Elem()
+			_ = urlstruct.ScanField(ctx, v, test.tag, values)
+			_ = urlstruct.ScanField(ctx, v, test.tag, nil)
+
+			// Fields are always addressable, but the scanners must not
+			// panic on values that are not.
+			if test.typ == reflect.TypeOf(CustomField{}) {
+				err := urlstruct.ScanField(ctx, reflect.Zero(test.typ), test.tag, values)
+				if err == nil {
+					t.Fatalf("scanning a non-addressable %s succeeded", test.typ)
+				}
+			}
+		}
+	})
+}
+
+func FuzzDuration(f *testing.F) {
+	f.Add(int64(0))
+	f.Add(int64(time.Hour + time.Millisecond))
+	f.Add(int64(-36 * time.Hour))
+	f.Add(int64(1<<63 - 1))
+	f.Add(int64(-1 << 63))
+
+	formats := []urlstruct.DurationFormat{
+		urlstruct.DurationGo,
+		urlstruct.DurationISO8601,
+		urlstruct.DurationAny,
+	}
+	f.Fuzz(func(t *testing.T, n int64) {
+		dur := time.Duration(n)
+		for _, format := range formats {
+			s := format.Format(dur)
+			got, err := format.Parse(s)
+			if err != nil {
+				t.Fatalf("%s.Parse(%q) failed: %s", format, s, err)
+			}
+			if got != dur {
+				t.Fatalf("%s.Parse(%q) = %d, wanted %d", format, s, got, dur)
+			}
+		}
+	})
+}
+
+func FuzzParseDuration(f *testing.F) {
+	f.Add("1h30m")
+	f.Add("-1.5d")
+	f.Add("2w3d4h")
+	f.Add("P1W")
+	f.Add("-PT0,5S")
+	f.Add("P1Y")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		dur, err := urlstruct.DurationAny.Parse(s)
+		if err != nil {
+			return
+		}
+
+		iso := urlstruct.DurationISO8601.Format(dur)
+		got, err := urlstruct.DurationISO8601.Parse(iso)
+		if err != nil {
+			t.Fatalf("Parse(%q) of %q failed: %s", iso, s, err)
+		}
+		if got != dur {
+			t.Fatalf("Parse(%q) = %d, wanted %d", iso, got, dur)
+		}
+	})
+}
+
+func FuzzRange(f *testing.F) {
+	f.Add("1..10")
+	f.Add("..10")
+	f.Add("1..")
+	f.Add("5")
+	f.Add("[1,10)")
+	f.Add("(1.5,]")
+	f.Add("[,Inf)")
+	f.Add("1e300..-1e300")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		checkRangeRoundTrip[int64](t, s)
+		checkRangeRoundTrip[uint8](t, s)
+		checkRangeRoundTrip[float64](t, s)
+	})
+}
+
+func checkRangeRoundTrip[T urlstruct.Number](t *testing.T, s string) {
+	var r urlstruct.Range[T]
+	if err := r.UnmarshalText([]byte(s)); err != nil {
+		return
+	}
+
+	b, err := r.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got urlstruct.Range[T]
+	if err := got.UnmarshalText(b); err != nil {
+		t.Fatalf("UnmarshalText(%q) of %q failed: %s", b, s, err)
+	}
+	if got != r {
+		t.Fatalf("UnmarshalText(%q) = %+v, wanted %+v", b, got, r)
+	}
+}
+
+func FuzzTimeRange(f *testing.F) {
+	f.Add("2024-01-01..2024-02-01")
+	f.Add("2024-01-01T10:00:00+02:00..")
+	f.Add("..1700000000")
+	f.Add("20240101T000000..20240101T000000Z")
+
+	ctx := urlstruct.WithLocation(context.Background(), time.UTC)
+	f.Fuzz(func(t *testing.T, s string) {
+		var r urlstruct.TimeRange
+		if err := urlstruct.Scan(ctx, &r, []string{s}); err != nil {
+			return
+		}
+		if !isFormattable(r.Start) || !isFormattable(r.End) {
+			return
+		}
+
+		formatted := r.String()
+		var got urlstruct.TimeRange
+		if err := urlstruct.Scan(ctx, &got, []string{formatted}); err != nil {
+			t.Fatalf("Scan(%q) of %q failed: %s", formatted, s, err)
+		}
+		if !got.Start.Equal(r.Start) || !got.End.Equal(r.End) {
+			t.Fatalf("Scan(%q) = %s, wanted %s", formatted, got, r)
+		}
+	})
+}
+
+func FuzzDate(f *testing.F) {
+	f.Add("2024-02-29")
+	f.Add("0000-01-01")
+	f.Add("2023-02-29")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		date, err := urlstruct.ParseDate(s)
+		if err != nil {
+			return
+		}
+
+		got, err := urlstruct.ParseDate(date.String())
+		if err != nil {
+			t.Fatalf("ParseDate(%q) of %q failed: %s", date, s, err)
+		}
+		if got != date {
+			t.Fatalf("ParseDate(%q) = %s, wanted %s", date, got, date)
+		}
+	})
+}
+
+// isFormattable reports whether the time is zero or is in the years that
+// RFC 3339 can represent.
+func isFormattable(tm time.Time) bool {
+	if tm.IsZero() {
+		return true
+	}
+	year := tm.Year()
+	return year >= 0 && year <= 9999
+}
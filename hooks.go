@@ -0,0 +1,100 @@
+package urlstruct
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+)
+
+// BeforeDecoder prepares the struct before the params are decoded, e.g.
+// sets defaults that depend on the context. BeforeDecode can change the
+// values that are decoded; the values passed to Unmarshal are not changed.
+//
+// Nested structs get the same values as the struct being decoded, so their
+// own params keep the `name[key]` form, e.g. `sub[limit]`.
+type BeforeDecoder interface {
+	BeforeDecode(ctx context.Context, values url.Values) error
+}
+
+// AfterDecoder checks the struct after the params are decoded and the
+// Unmarshalers are called, e.g. validates the fields that depend on each
+// other.
+type AfterDecoder interface {
+	AfterDecode(ctx context.Context) error
+}
+
+var (
+	beforeDecoderType = reflect.TypeOf((*BeforeDecoder)(nil)).Elem()
+	afterDecoderType  = reflect.TypeOf((*AfterDecoder)(nil)).Elem()
+)
+
+// CloneValues returns a deep copy of the values.
+func CloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for name, vs := range values {
+		clone[name] = append([]string(nil), vs...)
+	}
+	return clone
+}
+
+// hookSet records which of BeforeDecode and AfterDecode a struct has.
+type hookSet struct {
+	before bool
+	after  bool
+}
+
+func typeHooks(typ reflect.Type) hookSet {
+	ptr := reflect.PtrTo(typ)
+	return hookSet{
+		before: ptr.Implements(beforeDecoderType),
+		after:  ptr.Implements(afterDecoderType),
+	}
+}
+
+// addNestedHooks adds the hooks of the nested struct at the index.
+func (s *StructInfo) addNestedHooks(index []int, nested *StructInfo) {
+	for _, idx := range nested.beforeHooks {
+		s.beforeHooks = append(s.beforeHooks, joinIndex(index, idx))
+	}
+	for _, idx := range nested.afterHooks {
+		s.afterHooks = append(s.afterHooks, joinIndex(index, idx))
+	}
+}
+
+// hook returns the struct at the index that has the hook, allocating the
+// nil pointers to embedded structs.
+func (d structDecoder) hook(index []int) interface{} {
+	v := fieldByIndex(d.v, index)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v.Interface()
+	}
+	return v.Addr().Interface()
+}
+
+// beforeDecode calls BeforeDecode of the struct and then of the nested and
+// embedded structs in the field order. It returns the values to decode.
+func (d structDecoder) beforeDecode(ctx context.Context, values url.Values) (url.Values, error) {
+	values = CloneValues(values)
+	for _, idx := range d.sinfo.beforeHooks {
+		h := d.hook(idx).(BeforeDecoder)
+		if err := h.BeforeDecode(ctx, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// afterDecode calls AfterDecode of the nested and embedded structs in the
+// field order and then of the struct.
+func (d structDecoder) afterDecode(ctx context.Context) error {
+	for _, idx := range d.sinfo.afterHooks {
+		h := d.hook(idx).(AfterDecoder)
+		if err := h.AfterDecode(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
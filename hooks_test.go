@@ -0,0 +1,247 @@
+package urlstruct_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type (
+	hookLogKey struct{}
+	tenantKey  struct{}
+)
+
+func logHook(ctx context.Context, s string) {
+	if log, ok := ctx.Value(hookLogKey{}).(*[]string); ok {
+		*log = append(*log, s)
+	}
+}
+
+type HookInner struct {
+	Depth int
+}
+
+var (
+	_ urlstruct.BeforeDecoder = (*HookInner)(nil)
+	_ urlstruct.AfterDecoder  = (*HookInner)(nil)
+)
+
+func (h *HookInner) BeforeDecode(ctx context.Context, values url.Values) error {
+	logHook(ctx, "inner.before")
+	h.Depth = 1
+	return nil
+}
+
+func (h *HookInner) AfterDecode(ctx context.Context) error {
+	logHook(ctx, "inner.after")
+	return nil
+}
+
+type HookBase struct {
+	Tenant string
+	From   int
+	To     int
+}
+
+func (b *HookBase) BeforeDecode(ctx context.Context, values url.Values) error {
+	logHook(ctx, "base.before")
+	return nil
+}
+
+func (b *HookBase) AfterDecode(ctx context.Context) error {
+	logHook(ctx, "base.after")
+	if b.From > b.To {
+		return errors.New("from is after to")
+	}
+	return nil
+}
+
+type HookPaging struct {
+	Page int
+}
+
+func (p *HookPaging) BeforeDecode(ctx context.Context, values url.Values) error {
+	logHook(ctx, "paging.before")
+	p.Page = 1
+	return nil
+}
+
+func (p *HookPaging) AfterDecode(ctx context.Context) error {
+	logHook(ctx, "paging.after")
+	return nil
+}
+
+type HookOuter struct {
+	Sub HookInner
+}
+
+type HookOptions struct {
+	Size int
+
+	seen []string
+}
+
+func (o *HookOptions) BeforeDecode(ctx context.Context, values url.Values) error {
+	for name := range values {
+		o.seen = append(o.seen, name)
+	}
+	if _, ok := values["opts[size]"]; !ok {
+		values.Set("opts[size]", "20")
+	}
+	return nil
+}
+
+type LifecycleFilter struct {
+	HookBase
+	Sub HookInner
+
+	Query string
+	Limit int
+}
+
+var _ urlstruct.BeforeDecoder = (*LifecycleFilter)(nil)
+
+// BeforeDecode shadows HookBase.BeforeDecode, while HookBase.AfterDecode
+// is promoted.
+func (f *LifecycleFilter) BeforeDecode(ctx context.Context, values url.Values) error {
+	logHook(ctx, "filter.before")
+	if values.Get("tenant") != "" {
+		return errors.New("tenant can't be set")
+	}
+	f.Tenant, _ = ctx.Value(tenantKey{}).(string)
+
+	if q, ok := values["q"]; ok {
+		values["query"] = q
+		delete(values, "q")
+	}
+	if _, ok := values["limit"]; !ok {
+		values.Set("limit", "10")
+	}
+	return nil
+}
+
+var _ = Describe("Decode hooks", func() {
+	var log []string
+	var ctx context.Context
+
+	BeforeEach(func() {
+		log = nil
+		ctx = context.WithValue(context.TODO(), hookLogKey{}, &log)
+	})
+
+	It("calls the hooks in order", func() {
+		f := new(LifecycleFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"sub[depth]": {"2"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Sub.Depth).To(Equal(2))
+		Expect(log).To(Equal([]string{
+			"filter.before",
+			"inner.before",
+			"inner.after",
+			"base.after",
+		}))
+	})
+
+	It("decodes the values changed by BeforeDecode", func() {
+		ctx = context.WithValue(ctx, tenantKey{}, "acme")
+		values := url.Values{
+			"q":    {"go"},
+			"from": {"1"},
+			"to":   {"2"},
+		}
+		f := new(LifecycleFilter)
+		err := urlstruct.Unmarshal(ctx, values, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Tenant).To(Equal("acme"))
+		Expect(f.Query).To(Equal("go"))
+		Expect(f.Limit).To(Equal(10))
+		Expect(f.Sub.Depth).To(Equal(1))
+
+		Expect(values).To(Equal(url.Values{
+			"q":    {"go"},
+			"from": {"1"},
+			"to":   {"2"},
+		}))
+	})
+
+	It("passes the same values to the hooks of nested structs", func() {
+		f := new(struct {
+			Opts  HookOptions
+			Query string
+		})
+		err := urlstruct.Unmarshal(ctx, url.Values{"query": {"go"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Opts.seen).To(Equal([]string{"query"}))
+		Expect(f.Opts.Size).To(Equal(20))
+
+		f.Opts = HookOptions{}
+		err = urlstruct.Unmarshal(ctx, url.Values{"opts[size]": {"5"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Opts.seen).To(Equal([]string{"opts[size]"}))
+		Expect(f.Opts.Size).To(Equal(5))
+	})
+
+	It("returns the errors of the hooks", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"tenant": {"x"}}, new(LifecycleFilter))
+		Expect(err).To(MatchError("tenant can't be set"))
+		Expect(log).To(Equal([]string{"filter.before"}))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"from": {"2"}, "to": {"1"}}, new(LifecycleFilter))
+		Expect(err).To(MatchError("from is after to"))
+	})
+
+	It("calls the hooks of embedded structs", func() {
+		f := new(HookBase)
+		err := urlstruct.Unmarshal(ctx, url.Values{}, &struct{ *HookBase }{f})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(log).To(Equal([]string{"base.before", "base.after"}))
+	})
+
+	It("calls the hooks of two embedded structs that define them", func() {
+		f := new(struct {
+			HookBase
+			HookPaging
+		})
+		err := urlstruct.Unmarshal(ctx, url.Values{"from": {"2"}, "to": {"3"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.From).To(Equal(2))
+		Expect(f.Page).To(Equal(1))
+		Expect(log).To(Equal([]string{
+			"base.before",
+			"paging.before",
+			"base.after",
+			"paging.after",
+		}))
+
+		log = nil
+		err = urlstruct.Unmarshal(ctx, url.Values{}, new(struct {
+			*HookBase
+			*HookPaging
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(log).To(Equal([]string{
+			"base.before",
+			"paging.before",
+			"base.after",
+			"paging.after",
+		}))
+	})
+
+	It("allocates the nil embedded pointers", func() {
+		f := new(struct {
+			*HookOuter
+			W int
+		})
+		err := urlstruct.Unmarshal(ctx, url.Values{"w": {"1"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.W).To(Equal(1))
+		Expect(f.HookOuter).NotTo(BeNil())
+		Expect(f.Sub.Depth).To(Equal(1))
+		Expect(log).To(Equal([]string{"inner.before", "inner.after"}))
+	})
+})
@@ -0,0 +1,36 @@
+// Package dominant resolves the fields and nested structs that share a name
+// the same way for the reflection-based decoder and urlstruct-gen.
+package dominant
+
+// Field returns the candidate that wins by the Go embedding rules: the
+// shallowest one or, if there are several at that depth, the only one with
+// an explicit tag name. For ambiguous names it returns the candidates at
+// the shallowest depth instead.
+func Field[T any](cands []T, key func(T) (depth int, tagged bool)) (winner T, ambiguous []T) {
+	minDepth := -1
+	var shallowest []T
+	for _, c := range cands {
+		depth, _ := key(c)
+		switch {
+		case minDepth == -1 || depth < minDepth:
+			minDepth = depth
+			shallowest = append(shallowest[:0], c)
+		case depth == minDepth:
+			shallowest = append(shallowest, c)
+		}
+	}
+	if len(shallowest) == 1 {
+		return shallowest[0], nil
+	}
+
+	var tagged []T
+	for _, c := range shallowest {
+		if _, ok := key(c); ok {
+			tagged = append(tagged, c)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], nil
+	}
+	return winner, shallowest
+}
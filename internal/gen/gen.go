@@ -0,0 +1,705 @@
+// Package gen generates reflection-free decoders for urlstruct.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/vmihailenco/tagparser"
+
+	"github.com/go-pg/urlstruct"
+	"github.com/go-pg/urlstruct/internal/dominant"
+)
+
+const header = "// Code generated by urlstruct-gen; DO NOT EDIT."
+
+// Generate returns the source of the decoders for the named struct types
+// declared in the package in dir.
+func Generate(dir string, typeNames []string) ([]byte, error) {
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &fileData{
+		Package: pkg.Name(),
+	}
+	for _, name := range typeNames {
+		dec, err := newDecoder(pkg, name)
+		if err != nil {
+			return nil, err
+		}
+		data.Decoders = append(data.Decoders, dec)
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("urlstruct-gen: can't format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// OutputFile returns the default name of the generated file.
+func OutputFile(dir string, typeNames []string) string {
+	return filepath.Join(dir, strings.ToLower(typeNames[0])+"_urlstruct.go")
+}
+
+func isGenerated(f *ast.File) bool {
+	for _, c := range f.Comments {
+		if c.Pos() > f.Package {
+			break
+		}
+		for _, line := range c.List {
+			if line.Text == header {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+type fileData struct {
+	Package  string
+	Decoders []*decoder
+}
+
+type decoder struct {
+	Type             string
+	Recv             string
+	Fields           []*field
+	Structs          []*field
+	Hooks            []string
+	BeforeHooks      []string
+	AfterHooks       []string
+	ParamUnmarshaler bool
+	FieldDecoder     bool
+
+	// FieldDecoderNames are the names of the fields decoded with Scan,
+	// for which DecodeURLField is called first. DecodeParam calls it
+	// for the other fields.
+	FieldDecoderNames string
+
+	// MapNames are the names of the map fields, which are decoded only
+	// from the name[key] params.
+	MapNames string
+
+	pkg *types.Package
+}
+
+type field struct {
+	Name string
+	Expr string
+	Dst  string
+
+	tagged   bool
+	noDecode bool
+	keyed    bool
+}
+
+func newDecoder(pkg *types.Package, name string) (*decoder, error) {
+	obj := pkg.Scope().Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("urlstruct-gen: type %s not found", name)
+	}
+	typ, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("urlstruct-gen: %s is not a type", name)
+	}
+	st, ok := typ.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("urlstruct-gen: %s is not a struct", name)
+	}
+
+	ptr := types.NewPointer(typ.Type())
+	if isDeclared(pkg, ptr, "UnmarshalValues") {
+		return nil, fmt.Errorf("urlstruct-gen: %s already has UnmarshalValues method", name)
+	}
+
+	d := &decoder{
+		Type:             name,
+		Recv:             receiverName(name),
+		ParamUnmarshaler: hasMethod(pkg, ptr, "UnmarshalParam", 3),
+		FieldDecoder:     hasMethod(pkg, ptr, "DecodeURLField", 2),
+
+		pkg: pkg,
+	}
+	if err := d.addFields(st, d.Recv); err != nil {
+		return nil, err
+	}
+	d.BeforeHooks = d.decodeHooks(typ.Type(), d.Recv, "BeforeDecode", 2, false)
+	d.AfterHooks = d.decodeHooks(typ.Type(), d.Recv, "AfterDecode", 1, true)
+
+	var err error
+	if d.Fields, err = resolveDuplicates(d.Fields); err != nil {
+		return nil, err
+	}
+	if d.Structs, err = resolveDuplicates(d.Structs); err != nil {
+		return nil, err
+	}
+
+	fields := d.Fields[:0]
+	for _, f := range d.Fields {
+		if !f.noDecode {
+			fields = append(fields, f)
+		}
+	}
+	d.Fields = fields
+
+	var maps []string
+	for _, f := range d.Fields {
+		if f.keyed {
+			maps = append(maps, fmt.Sprintf("%q", f.Name))
+		}
+	}
+	d.MapNames = strings.Join(maps, ", ")
+
+	if d.FieldDecoder {
+		var names []string
+		for _, f := range d.Fields {
+			if f.Dst != "" {
+				names = append(names, fmt.Sprintf("%q", f.Name))
+			}
+		}
+		d.FieldDecoderNames = strings.Join(names, ", ")
+	}
+
+	return d, nil
+}
+
+func (d *decoder) addFields(st *types.Struct, base string) error {
+	for i := 0; i < st.NumFields(); i++ {
+		sf := st.Field(i)
+		if !sf.Exported() && !sf.Embedded() {
+			continue
+		}
+
+		tag := reflect.StructTag(st.Tag(i)).Get("urlstruct")
+		expr := base + "." + sf.Name()
+
+		if !sf.Embedded() {
+			if err := d.addField(sf, tagparser.Parse(tag), expr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		typ := sf.Type()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			if _, ok := ptr.Elem().Underlying().(*types.Struct); ok {
+				return fmt.Errorf("urlstruct-gen: embedded pointer %s is not supported", expr)
+			}
+			continue
+		}
+		embedded, ok := typ.Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+
+		if d.isHookUnmarshaler(typ) {
+			d.Hooks = append(d.Hooks, expr)
+		}
+
+		if err := d.addFields(embedded, expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) addField(sf *types.Var, tag *tagparser.Tag, expr string) error {
+	if tag.Name == "-" {
+		return nil
+	}
+	if _, ok := tag.Options["alias"]; ok {
+		return fmt.Errorf("urlstruct-gen: aliases of %s are not supported", expr)
+	}
+
+	name := tag.Name
+	if name == "" {
+		name = sf.Name()
+	}
+	// Generated decoders are used only with the default naming.
+	name = urlstruct.SnakeCase(name)
+
+	typ := sf.Type()
+	if _, ok := typ.Underlying().(*types.Struct); ok {
+		d.Structs = append(d.Structs, &field{
+			Name:   name,
+			Expr:   expr,
+			tagged: tag.Name != "",
+		})
+	}
+
+	if d.isHookUnmarshaler(typ) {
+		d.Hooks = append(d.Hooks, expr)
+	}
+
+	_, binary := tag.Options["base64"]
+	dst, ok := scanDst(typ, expr, binary)
+	_, hasDecoder := tag.Options["decoder"]
+	if !ok && !hasDecoder {
+		return nil
+	}
+	if hasScanOptions(tag) {
+		// Scan does not know about the tag, so the field is decoded
+		// using reflection, which also calls the decoder method.
+		dst = ""
+	}
+	_, noDecode := tag.Options["nodecode"]
+	_, isMap := typ.Underlying().(*types.Map)
+	d.Fields = append(d.Fields, &field{
+		Name: name,
+		Expr: expr,
+		Dst:  dst,
+
+		tagged:   tag.Name != "",
+		noDecode: noDecode,
+		keyed: isMap && !hasDecoder &&
+			!hasUnmarshaler(typ, binary) && !hasUnmarshaler(types.NewPointer(typ), binary),
+	})
+	return nil
+}
+
+func hasScanOptions(tag *tagparser.Tag) bool {
+	for name := range tag.Options {
+		if name != "nodecode" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDuplicates mirrors the reflection-based decoder: the shallowest
+// field with the name wins or, at the same depth, the only field with an
+// explicit tag name. Other duplicates are ambiguous.
+func resolveDuplicates(fields []*field) ([]*field, error) {
+	groups := make(map[string][]*field, len(fields))
+	for _, f := range fields {
+		groups[f.Name] = append(groups[f.Name], f)
+	}
+
+	resolved := fields[:0]
+	for _, f := range fields {
+		group := groups[f.Name]
+		if len(group) == 1 {
+			resolved = append(resolved, f)
+			continue
+		}
+		if f != group[0] {
+			continue
+		}
+
+		winner, err := dominantField(group)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, winner)
+	}
+	return resolved, nil
+}
+
+func dominantField(fields []*field) (*field, error) {
+	winner, ambiguous := dominant.Field(fields, fieldKey)
+	if ambiguous == nil {
+		return winner, nil
+	}
+
+	exprs := make([]string, len(ambiguous))
+	for i, f := range ambiguous {
+		exprs[i] = f.Expr
+	}
+	return nil, fmt.Errorf("urlstruct-gen: name %q is used by %s",
+		fields[0].Name, strings.Join(exprs, " and "))
+}
+
+func fieldKey(f *field) (int, bool) {
+	return strings.Count(f.Expr, "."), f.tagged
+}
+
+func (d *decoder) isHookUnmarshaler(typ types.Type) bool {
+	ptr := types.NewPointer(typ)
+	return hasMethod(d.pkg, ptr, "UnmarshalValues", 2) &&
+		!hasMethod(d.pkg, ptr, "URLStructGenerated", 0)
+}
+
+// decodeHooks returns the receivers of the BeforeDecode or AfterDecode
+// hooks in the order the reflection-based decoder calls them: the struct
+// and then the nested structs or, for the after hooks, the other way round.
+func (d *decoder) decodeHooks(typ types.Type, expr, method string, numIn int, after bool) []string {
+	var hooks []string
+	self := hasMethod(d.pkg, types.NewPointer(typ), method, numIn)
+	if self && !after {
+		hooks = append(hooks, expr)
+	}
+	if st, ok := typ.Underlying().(*types.Struct); ok {
+		hooks = append(hooks, d.nestedHooks(st, expr, self, method, numIn, after)...)
+	}
+	if self && after {
+		hooks = append(hooks, expr)
+	}
+	return hooks
+}
+
+// nestedHooks returns the hooks of the fields of the struct. The hooks of
+// embedded structs are called by the field unless they are promoted to a
+// struct that has the hook, i.e. called is true.
+func (d *decoder) nestedHooks(
+	st *types.Struct, base string, called bool, method string, numIn int, after bool,
+) []string {
+	var hooks []string
+	for i := 0; i < st.NumFields(); i++ {
+		sf := st.Field(i)
+		if !sf.Exported() && !sf.Embedded() {
+			continue
+		}
+		tag := tagparser.Parse(reflect.StructTag(st.Tag(i)).Get("urlstruct"))
+		if tag.Name == "-" {
+			continue
+		}
+
+		expr := base + "." + sf.Name()
+		fieldStruct, ok := sf.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		if !sf.Embedded() {
+			hooks = append(hooks, d.decodeHooks(sf.Type(), expr, method, numIn, after)...)
+			continue
+		}
+
+		self := hasMethod(d.pkg, types.NewPointer(sf.Type()), method, numIn)
+		if self && !called && !after {
+			hooks = append(hooks, expr)
+		}
+		hooks = append(hooks, d.nestedHooks(fieldStruct, expr, called || self, method, numIn, after)...)
+		if self && !called && after {
+			hooks = append(hooks, expr)
+		}
+	}
+	return hooks
+}
+
+func receiverName(typeName string) string {
+	recv := strings.ToLower(typeName[:1])
+	switch recv {
+	case "c", "e", "k", "m", "n", "v", "_":
+		return "x"
+	}
+	return recv
+}
+
+//------------------------------------------------------------------------------
+
+// scanDst returns the argument passed to urlstruct.Scan for the field.
+// It reports false for the types the reflection-based decoder ignores.
+// Binary reports whether the field has the `base64` tag option.
+func scanDst(typ types.Type, expr string, binary bool) (string, bool) {
+	if hasUnmarshaler(typ, binary) || hasUnmarshaler(types.NewPointer(typ), binary) {
+		return "&" + expr, true
+	}
+	// Scan decodes pointers and Optional with reflection.
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		if _, ok := scalarKind(ptr.Elem(), binary); !ok {
+			return "", false
+		}
+		return "&" + expr, true
+	}
+	if named, ok := typ.(*types.Named); ok && isNamed(named.Origin(), "github.com/go-pg/urlstruct", "Optional") {
+		return "&" + expr, true
+	}
+
+	var elem types.Type
+	switch t := typ.Underlying().(type) {
+	case *types.Slice:
+		elem = t.Elem()
+	case *types.Array:
+		elem = t.Elem()
+	}
+	if elem != nil {
+		if _, ok := scalarKind(elem, binary); !ok {
+			return "", false
+		}
+		return "&" + expr, true
+	}
+
+	basic, ok := scalarKind(typ, binary)
+	if !ok {
+		return "", false
+	}
+	if basic != nil && !hasEnumValues(typ) {
+		if _, named := typ.(*types.Named); named {
+			// Converting to the underlying type avoids reflection in Scan.
+			return fmt.Sprintf("(*%s)(&%s)", basic.Name(), expr), true
+		}
+	}
+	return "&" + expr, true
+}
+
+// scalarKind reports whether the type can be scanned from a single value
+// and returns the underlying basic type when the type is scanned as one.
+func scalarKind(typ types.Type, binary bool) (*types.Basic, bool) {
+	if isNamed(typ, "time", "Time") ||
+		isNamed(typ, "github.com/go-pg/urlstruct", "TimeRange") {
+		return nil, true
+	}
+	if hasUnmarshaler(typ, binary) || hasUnmarshaler(types.NewPointer(typ), binary) {
+		return nil, true
+	}
+	if isNamed(typ, "time", "Duration") {
+		return nil, true
+	}
+	if value, ok := nullValue(typ); ok {
+		_, ok := scalarKind(value, binary)
+		return nil, ok
+	}
+	if m, ok := typ.(*types.Map); ok {
+		if _, ok := m.Elem().Underlying().(*types.Map); ok {
+			return nil, false
+		}
+		// The tag options apply to the values only.
+		_, keyOK := scalarKind(m.Key(), false)
+		_, elemOK := scalarKind(m.Elem(), binary)
+		return nil, keyOK && elemOK
+	}
+
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return nil, false
+	}
+	switch basic.Kind() {
+	case types.Bool, types.String,
+		types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
+		types.Float32, types.Float64:
+		return basic, true
+	}
+	return nil, false
+}
+
+// nullValue returns the type of the value field of the types like
+// the sql.Null* types and sql.Null[T]: structs that implement sql.Scanner
+// and have a value field followed by the Valid field.
+func nullValue(typ types.Type) (types.Type, bool) {
+	st, ok := typ.Underlying().(*types.Struct)
+	if !ok || st.NumFields() != 2 {
+		return nil, false
+	}
+	value, valid := st.Field(0), st.Field(1)
+	basic, ok := valid.Type().Underlying().(*types.Basic)
+	if !value.Exported() || valid.Name() != "Valid" || !ok || basic.Kind() != types.Bool {
+		return nil, false
+	}
+	if types.NewMethodSet(types.NewPointer(typ)).Lookup(nil, "Scan") == nil {
+		return nil, false
+	}
+	return value.Type(), true
+}
+
+func isNamed(typ types.Type, pkgPath, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+// The interfaces that Scan decodes with: encoding.TextUnmarshaler,
+// encoding.BinaryUnmarshaler and flag.Value.
+var (
+	textUnmarshalerType   = newInterface(newMethod("UnmarshalText", bytesType, errorType))
+	binaryUnmarshalerType = newInterface(newMethod("UnmarshalBinary", bytesType, errorType))
+	flagValueType         = newInterface(
+		newMethod("Set", types.Typ[types.String], errorType),
+		newMethod("String", nil, types.Typ[types.String]),
+	)
+)
+
+var (
+	bytesType = types.NewSlice(types.Typ[types.Byte])
+	errorType = types.Universe.Lookup("error").Type()
+)
+
+func newMethod(name string, param, result types.Type) *types.Func {
+	var params []*types.Var
+	if param != nil {
+		params = append(params, types.NewParam(token.NoPos, nil, "", param))
+	}
+	results := []*types.Var{types.NewParam(token.NoPos, nil, "", result)}
+	sig := types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(params...), types.NewTuple(results...), false)
+	return types.NewFunc(token.NoPos, nil, name, sig)
+}
+
+func newInterface(methods ...*types.Func) *types.Interface {
+	return types.NewInterfaceType(methods, nil).Complete()
+}
+
+// hasUnmarshaler reports whether the type decodes itself. Like in Scan,
+// encoding.BinaryUnmarshaler is used only with the `base64` tag option.
+func hasUnmarshaler(typ types.Type, binary bool) bool {
+	return types.Implements(typ, textUnmarshalerType) ||
+		binary && types.Implements(typ, binaryUnmarshalerType) ||
+		types.Implements(typ, flagValueType)
+}
+
+// hasEnumValues reports whether the type implements urlstruct.Enum, which
+// Scan checks only for the type itself and not for the underlying type.
+func hasEnumValues(typ types.Type) bool {
+	return types.NewMethodSet(types.NewPointer(typ)).Lookup(nil, "EnumValues") != nil
+}
+
+func hasMethod(pkg *types.Package, typ types.Type, name string, numIn int) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, pkg, name)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	return fn.Type().(*types.Signature).Params().Len() == numIn
+}
+
+// isDeclared reports whether the method is declared on the type itself
+// rather than promoted from an embedded field.
+func isDeclared(pkg *types.Package, typ types.Type, name string) bool {
+	obj, index, _ := types.LookupFieldOrMethod(typ, true, pkg, name)
+	_, ok := obj.(*types.Func)
+	return ok && len(index) == 1
+}
+
+//------------------------------------------------------------------------------
+
+var fileTemplate = template.Must(template.New("file").Parse(header + `
+
+package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/go-pg/urlstruct"
+)
+{{range $dec := .Decoders}}
+var _ urlstruct.GeneratedUnmarshaler = (*{{.Type}})(nil)
+
+func (*{{.Type}}) URLStructGenerated() {}
+
+func ({{.Recv}} *{{.Type}}) UnmarshalValues(ctx context.Context, values url.Values) error {
+	{{- if .BeforeHooks}}
+	values = urlstruct.CloneValues(values)
+	{{- range .BeforeHooks}}
+	if err := {{.}}.BeforeDecode(ctx, values); err != nil {
+		return err
+	}
+	{{- end}}
+	{{end}}
+	var maps map[string][]string
+
+	for name, vs := range values {
+		name, key := urlstruct.SplitParam(name)
+		if key != "" {
+			{{- if .Structs}}
+			switch name {
+			{{- range .Structs}}
+			case {{printf "%q" .Name}}:
+				if err := urlstruct.DecodeNestedParam(ctx, &{{.Expr}}, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			{{- end}}
+			}
+			{{end}}
+			if len(vs) == 0 {
+				continue
+			}
+			if maps == nil {
+				maps = make(map[string][]string)
+			}
+			{{- if .MapNames}}
+			switch name {
+			case {{.MapNames}}:
+				if err := urlstruct.CheckMapEntries(ctx, name, len(maps[name])/2+1); err != nil {
+					return err
+				}
+			}
+			{{- end}}
+			maps[name] = append(maps[name], key, vs[0])
+			continue
+		}
+		{{- if .MapNames}}
+
+		switch name {
+		case {{.MapNames}}:
+			continue
+		}
+		{{- end}}
+
+		if err := {{.Recv}}.urlstructDecodeParam(ctx, name, vs); err != nil {
+			return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+		}
+	}
+
+	for name, vs := range maps {
+		if err := {{.Recv}}.urlstructDecodeParam(ctx, name, vs); err != nil {
+			return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+		}
+	}
+	{{range .Hooks}}
+	if err := {{.}}.UnmarshalValues(ctx, values); err != nil {
+		return err
+	}
+	{{- end}}
+	{{- range .AfterHooks}}
+	if err := {{.}}.AfterDecode(ctx); err != nil {
+		return err
+	}
+	{{- end}}
+
+	return nil
+}
+
+func ({{.Recv}} *{{.Type}}) urlstructDecodeParam(ctx context.Context, name string, vs []string) error {
+	{{- if .FieldDecoderNames}}
+	switch name {
+	case {{.FieldDecoderNames}}:
+		if handled, err := {{.Recv}}.DecodeURLField(name, vs); handled || err != nil {
+			return err
+		}
+	}
+	{{end}}
+	switch name {
+	{{- range .Fields}}
+	case {{printf "%q" .Name}}:
+		{{- if .Dst}}
+		return urlstruct.Scan(ctx, {{.Dst}}, vs)
+		{{- else}}
+		return urlstruct.DecodeParam(ctx, {{$dec.Recv}}, name, vs)
+		{{- end}}
+	{{- end}}
+	}
+	{{- if .ParamUnmarshaler}}
+	return {{.Recv}}.UnmarshalParam(ctx, name, vs)
+	{{- else}}
+	return nil
+	{{- end}}
+}
+{{end}}`))
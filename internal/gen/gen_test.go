@@ -0,0 +1,182 @@
+package gen_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-pg/urlstruct/internal/gen"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGolden(t *testing.T) {
+	const dir = "../gentest"
+	types := []string{"Filter"}
+
+	src, err := gen.Generate(dir, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := gen.OutputFile(dir, types)
+	if *update {
+		if err := os.WriteFile(filename, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	golden, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, golden) {
+		t.Fatalf("%s is out of date; run go test -update\n%s", filename, src)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	tests := []struct {
+		typ string
+		err string
+	}{
+		{"Missing", "urlstruct-gen: type Missing not found"},
+		{"Status", "urlstruct-gen: Status is not a struct"},
+		{"SubFilter", "urlstruct-gen: SubFilter already has UnmarshalValues method"},
+		{"AmbiguousFilter", `urlstruct-gen: name "name" is used by a.Left.Name and a.Right.Name`},
+		{"AliasFilter", "urlstruct-gen: aliases of a.AuthorID are not supported"},
+	}
+	for _, test := range tests {
+		_, err := gen.Generate("../gentest", []string{test.typ})
+		if err == nil || err.Error() != test.err {
+			t.Fatalf("got %v, wanted %q", err, test.err)
+		}
+	}
+}
+
+func TestTypeErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{
+			"package p\n\ntype F struct{ A int }\n\nvar x int = \"x\"\n",
+			"cannot use \"x\"",
+		},
+		{
+			"package p\n\ntype F struct{ A Missing }\n",
+			"undefined: Missing",
+		},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(test.src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := gen.Generate(dir, []string{"F"})
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("got %v, wanted %q", err, test.err)
+		}
+	}
+}
+
+func TestGeneratedMethodsAreIgnored(t *testing.T) {
+	const src = `package p
+
+import (
+	"context"
+	"net/url"
+)
+
+type F struct{ A int }
+
+func decode(f *F) error {
+	return f.UnmarshalValues(context.Background(), url.Values{})
+}
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gen.Generate(dir, []string{"F"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildConstraints(t *testing.T) {
+	files := map[string]string{
+		"p.go":         "package p\n\ntype F struct{ A string }\n",
+		"os_linux.go":  "package p\n\nconst osName = \"linux\"\n",
+		"os_darwin.go": "package p\n\nconst osName = \"darwin\"\n",
+		"os_other.go":  "//go:build !linux && !darwin\n\npackage p\n\nconst osName = \"other\"\n",
+		"ignored.go":   "//go:build ignore\n\npackage main\n",
+	}
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := gen.Generate(dir, []string{"F"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnmarshalerSignatures(t *testing.T) {
+	const src = `package p
+
+type Code int
+
+// UnmarshalText does not implement encoding.TextUnmarshaler.
+func (c *Code) UnmarshalText(s string) error {
+	return nil
+}
+
+type F struct{ Code Code }
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := gen.Generate(dir, []string{"F"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte("urlstruct.Scan(ctx, (*int)(&f.Code), vs)")) {
+		t.Fatalf("Code is scanned as an unmarshaler:\n%s", out)
+	}
+}
+
+func TestBinaryUnmarshalerNeedsBase64(t *testing.T) {
+	const src = `package p
+
+type Blob struct{ b []byte }
+
+func (b *Blob) UnmarshalBinary(data []byte) error {
+	b.b = data
+	return nil
+}
+
+type F struct {
+	Raw    Blob
+	Base64 Blob ` + "`urlstruct:\",base64\"`" + `
+}
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := gen.Generate(dir, []string{"F"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("urlstruct.Scan(ctx, &f.Raw")) {
+		t.Fatalf("Raw is decoded without the base64 option:\n%s", out)
+	}
+	if !bytes.Contains(out, []byte(`case "base64":`)) {
+		t.Fatalf("Base64 is not decoded:\n%s", out)
+	}
+}
@@ -0,0 +1,153 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// loadPackage parses and type checks the package in dir. Like the go
+// command, it uses only the files that match the build constraints for
+// the current GOOS and GOARCH.
+func loadPackage(dir string) (*types.Package, error) {
+	fset := token.NewFileSet()
+	var matchErr error
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		if strings.HasSuffix(fi.Name(), "_test.go") {
+			return false
+		}
+		ok, err := build.Default.MatchFile(dir, fi.Name())
+		if err != nil && matchErr == nil {
+			matchErr = err
+		}
+		return ok
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if matchErr != nil {
+		return nil, matchErr
+	}
+
+	var files []*ast.File
+	var name string
+	for pkgName, pkg := range pkgs {
+		if name != "" {
+			return nil, fmt.Errorf("urlstruct-gen: found packages %s and %s in %s",
+				name, pkgName, dir)
+		}
+		name = pkgName
+
+		for _, f := range pkg.Files {
+			// Previously generated code is replaced and must not be type checked.
+			if isGenerated(f) {
+				continue
+			}
+			files = append(files, f)
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("urlstruct-gen: no Go files in %s", dir)
+	}
+
+	exports, err := listExports(dir, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var typeErr error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			file, ok := exports[path]
+			if !ok {
+				return nil, fmt.Errorf("urlstruct-gen: can't find export data for %q", path)
+			}
+			return os.Open(file)
+		}),
+		FakeImportC: true,
+		Error: func(err error) {
+			// Code that depends on the generated methods does not type check.
+			if typeErr == nil && !usesGeneratedMethods(err) {
+				typeErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(name, fset, files, nil)
+	if typeErr != nil {
+		return nil, fmt.Errorf("urlstruct-gen: %w", typeErr)
+	}
+	return pkg, nil
+}
+
+// listExports returns the export data files of the packages imported by
+// the files, which the go command builds if needed.
+func listExports(dir string, files []*ast.File) (map[string]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || path == "C" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"list", "-e", "-export", "-json=ImportPath,Export,Error", "--"}, paths...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("urlstruct-gen: go list: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	exports := make(map[string]string, len(paths))
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg struct {
+			ImportPath string
+			Export     string
+			Error      *struct{ Err string }
+		}
+		if err := dec.Decode(&pkg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("urlstruct-gen: go list: %w", err)
+		}
+		if pkg.Error != nil {
+			return nil, fmt.Errorf("urlstruct-gen: %s", pkg.Error.Err)
+		}
+		exports[pkg.ImportPath] = pkg.Export
+	}
+	return exports, nil
+}
+
+func usesGeneratedMethods(err error) bool {
+	msg := err.Error()
+	for _, name := range []string{"UnmarshalValues", "URLStructGenerated", "urlstructDecodeParam"} {
+		if strings.Contains(msg, name) {
+			return true
+		}
+	}
+	return false
+}
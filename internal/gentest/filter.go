@@ -0,0 +1,243 @@
+// Package gentest contains structs with decoders generated by urlstruct-gen.
+package gentest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-pg/urlstruct"
+)
+
+//go:generate go run ../../cmd/urlstruct-gen -type=Filter
+
+type Status string
+
+type Level string
+
+func (Level) EnumValues() []string {
+	return []string{"debug", "info", "error"}
+}
+
+func (Level) EnumAliases() map[string]string {
+	return map[string]string{"warn": "error"}
+}
+
+type CustomField struct {
+	S string
+}
+
+func (f *CustomField) UnmarshalText(text []byte) error {
+	f.S = string(text)
+	return nil
+}
+
+type Token []byte
+
+func (t *Token) UnmarshalBinary(data []byte) error {
+	*t = append((*t)[:0], data...)
+	return nil
+}
+
+type SubFilter struct {
+	Count  int
+	Labels map[string]string
+}
+
+func (f *SubFilter) UnmarshalValues(ctx context.Context, values url.Values) error {
+	f.Count++
+	return nil
+}
+
+type StructMap struct {
+	Foo        string
+	Bar        string
+	UnknownMap map[string][]string
+}
+
+func (s *StructMap) UnmarshalParam(ctx context.Context, name string, values []string) error {
+	if s.UnknownMap == nil {
+		s.UnknownMap = make(map[string][]string)
+	}
+	s.UnknownMap[name] = values
+	return nil
+}
+
+// Null is shaped like sql.Null[T], which requires Go 1.22.
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+func (n *Null[T]) Scan(src interface{}) error {
+	n.V, n.Valid = src.(T)
+	return nil
+}
+
+type Paging struct {
+	Limit  int
+	Offset int
+}
+
+func (p *Paging) BeforeDecode(ctx context.Context, values url.Values) error {
+	p.Limit = 10
+	return nil
+}
+
+func (p *Paging) AfterDecode(ctx context.Context) error {
+	if p.Limit > 100 {
+		return errors.New("limit is over 100")
+	}
+	return nil
+}
+
+type Embedded struct {
+	EmbeddedField string
+	Shadowed      string
+	Sort          []string `urlstruct:",decoder:DecodeSort"`
+	Order         string
+}
+
+func (e *Embedded) DecodeSort(ctx context.Context, values []string) error {
+	e.Sort = nil
+	for _, s := range values {
+		e.Sort = append(e.Sort, strings.Split(s, ",")...)
+	}
+	return nil
+}
+
+func (e *Embedded) DecodeURLField(name string, values []string) (bool, error) {
+	if name != "order" {
+		return false, nil
+	}
+	e.Order = strings.ToUpper(values[0])
+	return true, nil
+}
+
+func (e *Embedded) BeforeDecode(ctx context.Context, values url.Values) error {
+	if q, ok := values["q"]; ok {
+		values["field"] = q
+		delete(values, "q")
+	}
+	return nil
+}
+
+func (e *Embedded) AfterDecode(ctx context.Context) error {
+	if e.Order != "" && len(e.Sort) == 0 {
+		return errors.New("order requires sort")
+	}
+	return nil
+}
+
+// Window has hooks like Embedded, so neither is promoted to Filter.
+type Window struct {
+	WindowDays int
+}
+
+func (w *Window) BeforeDecode(ctx context.Context, values url.Values) error {
+	w.WindowDays = 7
+	return nil
+}
+
+func (w *Window) AfterDecode(ctx context.Context) error {
+	if w.WindowDays > 30 {
+		return errors.New("window is over 30 days")
+	}
+	return nil
+}
+
+type Filter struct {
+	unexported string //nolint:unused,structcheck
+
+	Embedded
+	Window
+	Sub    SubFilter
+	SMap   StructMap
+	Paging Paging
+
+	Field    string
+	FieldNEQ string `urlstruct:"neq"`
+	FieldLT  int8
+	FieldLTE int16
+	FieldGT  int32
+	FieldGTE int64
+	Uint     uint16
+	Float    float32
+	Bool     bool
+	Archived *bool
+	Status   Status
+	Level    Level
+	Skipped  string `urlstruct:"-"`
+	NoDecode string `urlstruct:",nodecode"`
+	Shadowed string
+	Label    string
+	Title    string `urlstruct:"label"`
+
+	Multi    []string
+	MultiNEQ []int
+	Floats   []float64
+	Bools    []bool
+	Int8s    []int8
+	Times    []time.Time
+	Statuses []Status
+	Levels   []Level
+	Point    [2]float64
+	Data     []byte `urlstruct:",base64"`
+
+	Time     time.Time
+	Period   urlstruct.TimeRange
+	Price    urlstruct.Range[float64]
+	Count    urlstruct.Range[int]
+	Duration time.Duration
+
+	NullBool    sql.NullBool
+	NullInt64   sql.NullInt64
+	NullFloat64 sql.NullFloat64
+	NullString  sql.NullString
+	NullTime    sql.NullTime
+	NullInt32   sql.NullInt32
+	NullInt16   sql.NullInt16
+	NullByte    sql.NullByte
+	NullLevel   Null[Level]
+	NullTimes   []sql.NullTime
+
+	Score *float64
+	Since *time.Time
+	Limit urlstruct.Optional[int]
+	Tags  urlstruct.Optional[[]string]
+
+	Map       map[string]string
+	Counts    map[Level]int
+	Owners    map[uuid.UUID]*CustomField
+	Custom    CustomField
+	CustomPtr *CustomField
+	Token     Token `urlstruct:",base64"`
+
+	Omit []byte `pg:"-"`
+
+	Uuid []uuid.UUID
+}
+
+// AliasFilter is used to test that urlstruct-gen rejects aliases.
+type AliasFilter struct {
+	AuthorID int `urlstruct:",alias:authorId"`
+}
+
+type Left struct {
+	Name string
+}
+
+type Right struct {
+	Name string
+}
+
+// AmbiguousFilter is used to test that urlstruct-gen rejects ambiguous names.
+type AmbiguousFilter struct {
+	Left
+	Right
+}
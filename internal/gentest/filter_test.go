@@ -0,0 +1,268 @@
+package gentest
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/go-pg/urlstruct"
+)
+
+// reflectFilter has the same fields as Filter, but not the generated
+// methods, so it is decoded using reflection.
+type reflectFilter Filter
+
+var _ urlstruct.GeneratedUnmarshaler = (*Filter)(nil)
+
+func TestGeneratedMatchesReflection(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []url.Values{
+		{},
+		{
+			"unexported": {"test"},
+
+			"embedded_field": {"embedded"},
+			"shadowed":       {"outer"},
+			"window_days":    {"14"},
+
+			"sub[count]":   {"5"},
+			"sub[labels]":  {"1"},
+			"s_map[foo]":   {"foo_value"},
+			"s_map[bar]":   {"bar_value"},
+			"s_map[hello]": {"world"},
+
+			"field":     {"one"},
+			"neq":       {"two"},
+			"field_lt":  {"1"},
+			"field_lte": {"2"},
+			"field_gt":  {"3"},
+			"field_gte": {"4"},
+			"uint":      {"5"},
+			"float":     {"1.5"},
+			"bool":      {"true"},
+			"status":    {"active"},
+			"level":     {"warn"},
+			"skipped":   {"skipped"},
+			"no_decode": {"no_decode"},
+			"label":     {"label"},
+
+			"multi":     {"one", "two"},
+			"multi_neq": {"3", "4"},
+			"floats":    {"1.5", "2.5"},
+			"bools":     {"true", "false"},
+			"int8_s":    {"-1", "2"},
+			"times":     {"1970-01-01T00:00:00Z", "0"},
+			"statuses":  {"active", "deleted"},
+			"levels":    {"debug", "info"},
+			"point":     {"1", "2"},
+			"data":      {"aGVsbG8"},
+
+			"time":   {"1970-01-01T00:00:00Z"},
+			"period": {"2024-01-01..2024-02-01"},
+			"price":  {"[1.5,10)"},
+
+			"count[gt]":  {"1"},
+			"count[lte]": {"5"},
+			"duration":   {"1m"},
+
+			"null_bool":    {"t"},
+			"null_int64":   {"1234"},
+			"null_float64": {"1.234"},
+			"null_string":  {""},
+
+			"map[foo]":   {"bar"},
+			"map[hello]": {"world"},
+			"map[]":      {"invalid"},
+			"counts":     {"info", "1"},
+
+			"custom":     {"custom"},
+			"custom_ptr": {"custom_ptr"},
+			"omit":       {"1", "2"},
+			"uuid":       {"3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+		},
+		{
+			":field":  {"prefix"},
+			"multi[]": {"suffix"},
+		},
+		{
+			"null_int64":   {"null"},
+			"null_float64": {""},
+			"null_string":  {"null"},
+			"score":        {"1.5"},
+			"since":        {"2024-01-01"},
+			"limit":        {"null"},
+			"tags":         {"a", "b"},
+		},
+		{
+			"null_time":  {"2024-01-01"},
+			"null_int32": {"-1"},
+			"null_int16": {""},
+			"null_byte":  {"255"},
+			"null_level": {"warn"},
+			"null_times": {"0", "null"},
+		},
+		{
+			"counts[info]": {"1"},
+			"counts[warn]": {"2"},
+			"owners[3fa85f64-5717-4562-b3fc-2c963f66afa6]": {"owner"},
+			"token": {"AQI"},
+		},
+		{
+			"sort":  {"name,-id", "age"},
+			"order": {"desc"},
+			"q":     {"x"},
+		},
+		{
+			"paging[limit]":  {"20"},
+			"paging[offset]": {"40"},
+		},
+		{
+			"score": {""},
+			"since": {"null"},
+			"limit": {"10"},
+			"tags":  {""},
+		},
+		{
+			"bool":      {""},
+			"archived":  {"false"},
+			"bools":     {"", "0"},
+			"null_bool": {""},
+		},
+		{
+			"sub[count]": {},
+			"s_map[foo]": {},
+			"field":      {},
+			"bool":       {},
+			"archived":   {},
+			"level":      {},
+			"multi":      {},
+			"point":      {},
+			"data":       {},
+			"time":       {},
+			"period":     {},
+			"price":      {},
+			"count[gt]":  {},
+			"duration":   {},
+			"null_bool":  {},
+			"map[foo]":   {},
+			"custom":     {},
+			"custom_ptr": {},
+			"uuid":       {},
+		},
+	}
+
+	for _, values := range tests {
+		got := new(Filter)
+		if err := urlstruct.Unmarshal(ctx, values, got); err != nil {
+			t.Fatal(err)
+		}
+
+		wanted := new(reflectFilter)
+		if err := urlstruct.Unmarshal(ctx, values, wanted); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, (*Filter)(wanted)) {
+			t.Fatalf("got %#v, wanted %#v", got, wanted)
+		}
+	}
+}
+
+func TestGeneratedErrors(t *testing.T) {
+	ctx := context.Background()
+
+	for _, values := range []url.Values{
+		{"field_lt": {"x"}},
+		{"time": {"x"}},
+		{"period": {"2024-02-01..2024-01-01"}},
+		{"price": {"2..1"}},
+		{"level": {"trace"}},
+		{"levels": {"info", "trace"}},
+		{"sub[count]": {"x"}},
+		{"int8_s": {"128"}},
+		{"point": {"1"}},
+		{"data": {"!"}},
+		{"counts[fatal]": {"1"}},
+		{"counts[info]": {"x"}},
+		{"owners[x]": {"owner"}},
+		{"token": {"!"}},
+		{"order": {"desc"}},
+		{"paging[limit]": {"101"}},
+		{"window_days": {"31"}},
+	} {
+		errGenerated := urlstruct.Unmarshal(ctx, values, new(Filter))
+		errReflect := urlstruct.Unmarshal(ctx, values, new(reflectFilter))
+		if errGenerated == nil || errReflect == nil ||
+			errGenerated.Error() != errReflect.Error() {
+			t.Fatalf("got %v, wanted %v", errGenerated, errReflect)
+		}
+	}
+}
+
+func TestDecoderOptions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		decoder *urlstruct.Decoder
+		values  url.Values
+	}{
+		{
+			&urlstruct.Decoder{Naming: urlstruct.CamelCase},
+			url.Values{"fieldLt": {"1"}, "embeddedField": {"embedded"}, "field_lt": {"2"}},
+		},
+		{
+			&urlstruct.Decoder{IgnoreCase: true},
+			url.Values{"FIELD_LT": {"1"}, "Embedded_Field": {"embedded"}},
+		},
+		{
+			&urlstruct.Decoder{Repeat: urlstruct.RepeatLast},
+			url.Values{"field_lt": {"2", "1"}, "embedded_field": {"other", "embedded"}},
+		},
+	}
+	for _, test := range tests {
+		got := new(Filter)
+		if err := test.decoder.Unmarshal(ctx, test.values, got); err != nil {
+			t.Fatal(err)
+		}
+		if got.FieldLT != 1 || got.EmbeddedField != "embedded" {
+			t.Fatalf("got %d and %q, wanted 1 and embedded", got.FieldLT, got.EmbeddedField)
+		}
+
+		wanted := new(reflectFilter)
+		if err := test.decoder.Unmarshal(ctx, test.values, wanted); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, (*Filter)(wanted)) {
+			t.Fatalf("got %#v, wanted %#v", got, wanted)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+	d := &urlstruct.Decoder{Limits: urlstruct.Limits{MaxMapEntries: 1}}
+
+	errValues := url.Values{"counts[info]": {"1"}, "counts[warn]": {"2"}}
+	errGenerated := d.Unmarshal(ctx, errValues, new(Filter))
+	errReflect := d.Unmarshal(ctx, errValues, new(reflectFilter))
+	if errGenerated == nil || errReflect == nil ||
+		errGenerated.Error() != errReflect.Error() {
+		t.Fatalf("got %v, wanted %v", errGenerated, errReflect)
+	}
+
+	// The params of nested structs are not map entries.
+	values := url.Values{"sub[count]": {"1"}, "sub[labels]": {"x"}, "s_map[foo]": {"foo"}, "s_map[bar]": {"bar"}}
+	got := new(Filter)
+	if err := d.Unmarshal(ctx, values, got); err != nil {
+		t.Fatal(err)
+	}
+	wanted := new(reflectFilter)
+	if err := d.Unmarshal(ctx, values, wanted); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, (*Filter)(wanted)) {
+		t.Fatalf("got %#v, wanted %#v", got, wanted)
+	}
+}
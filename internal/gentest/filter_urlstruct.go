@@ -0,0 +1,301 @@
+// Code generated by urlstruct-gen; DO NOT EDIT.
+
+package gentest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/go-pg/urlstruct"
+)
+
+var _ urlstruct.GeneratedUnmarshaler = (*Filter)(nil)
+
+func (*Filter) URLStructGenerated() {}
+
+func (f *Filter) UnmarshalValues(ctx context.Context, values url.Values) error {
+	values = urlstruct.CloneValues(values)
+	if err := f.Embedded.BeforeDecode(ctx, values); err != nil {
+		return err
+	}
+	if err := f.Window.BeforeDecode(ctx, values); err != nil {
+		return err
+	}
+	if err := f.Paging.BeforeDecode(ctx, values); err != nil {
+		return err
+	}
+
+	var maps map[string][]string
+
+	for name, vs := range values {
+		name, key := urlstruct.SplitParam(name)
+		if key != "" {
+			switch name {
+			case "sub":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Sub, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "s_map":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.SMap, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "paging":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Paging, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "time":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Time, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "period":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Period, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "price":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Price, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "count":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Count, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_bool":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullBool, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_int64":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullInt64, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_float64":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullFloat64, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_string":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullString, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_time":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullTime, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_int32":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullInt32, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_int16":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullInt16, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_byte":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullByte, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "null_level":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.NullLevel, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "limit":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Limit, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "tags":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Tags, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			case "custom":
+				if err := urlstruct.DecodeNestedParam(ctx, &f.Custom, key, vs); err != nil {
+					return fmt.Errorf("urlstruct: can't decode %q: %w", key, err)
+				}
+				continue
+			}
+
+			if len(vs) == 0 {
+				continue
+			}
+			if maps == nil {
+				maps = make(map[string][]string)
+			}
+			switch name {
+			case "map", "counts", "owners":
+				if err := urlstruct.CheckMapEntries(ctx, name, len(maps[name])/2+1); err != nil {
+					return err
+				}
+			}
+			maps[name] = append(maps[name], key, vs[0])
+			continue
+		}
+
+		switch name {
+		case "map", "counts", "owners":
+			continue
+		}
+
+		if err := f.urlstructDecodeParam(ctx, name, vs); err != nil {
+			return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+		}
+	}
+
+	for name, vs := range maps {
+		if err := f.urlstructDecodeParam(ctx, name, vs); err != nil {
+			return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+		}
+	}
+
+	if err := f.Sub.UnmarshalValues(ctx, values); err != nil {
+		return err
+	}
+	if err := f.Embedded.AfterDecode(ctx); err != nil {
+		return err
+	}
+	if err := f.Window.AfterDecode(ctx); err != nil {
+		return err
+	}
+	if err := f.Paging.AfterDecode(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (f *Filter) urlstructDecodeParam(ctx context.Context, name string, vs []string) error {
+	switch name {
+	case "embedded_field", "shadowed", "order", "window_days", "field", "neq", "field_lt", "field_lte", "field_gt", "field_gte", "uint", "float", "bool", "archived", "status", "level", "label", "multi", "multi_neq", "floats", "bools", "int8_s", "times", "statuses", "levels", "point", "time", "period", "price", "count", "duration", "null_bool", "null_int64", "null_float64", "null_string", "null_time", "null_int32", "null_int16", "null_byte", "null_level", "null_times", "score", "since", "limit", "tags", "map", "counts", "owners", "custom", "custom_ptr", "omit", "uuid":
+		if handled, err := f.DecodeURLField(name, vs); handled || err != nil {
+			return err
+		}
+	}
+
+	switch name {
+	case "embedded_field":
+		return urlstruct.Scan(ctx, &f.Embedded.EmbeddedField, vs)
+	case "shadowed":
+		return urlstruct.Scan(ctx, &f.Shadowed, vs)
+	case "sort":
+		return urlstruct.DecodeParam(ctx, f, name, vs)
+	case "order":
+		return urlstruct.Scan(ctx, &f.Embedded.Order, vs)
+	case "window_days":
+		return urlstruct.Scan(ctx, &f.Window.WindowDays, vs)
+	case "field":
+		return urlstruct.Scan(ctx, &f.Field, vs)
+	case "neq":
+		return urlstruct.Scan(ctx, &f.FieldNEQ, vs)
+	case "field_lt":
+		return urlstruct.Scan(ctx, &f.FieldLT, vs)
+	case "field_lte":
+		return urlstruct.Scan(ctx, &f.FieldLTE, vs)
+	case "field_gt":
+		return urlstruct.Scan(ctx, &f.FieldGT, vs)
+	case "field_gte":
+		return urlstruct.Scan(ctx, &f.FieldGTE, vs)
+	case "uint":
+		return urlstruct.Scan(ctx, &f.Uint, vs)
+	case "float":
+		return urlstruct.Scan(ctx, &f.Float, vs)
+	case "bool":
+		return urlstruct.Scan(ctx, &f.Bool, vs)
+	case "archived":
+		return urlstruct.Scan(ctx, &f.Archived, vs)
+	case "status":
+		return urlstruct.Scan(ctx, (*string)(&f.Status), vs)
+	case "level":
+		return urlstruct.Scan(ctx, &f.Level, vs)
+	case "label":
+		return urlstruct.Scan(ctx, &f.Title, vs)
+	case "multi":
+		return urlstruct.Scan(ctx, &f.Multi, vs)
+	case "multi_neq":
+		return urlstruct.Scan(ctx, &f.MultiNEQ, vs)
+	case "floats":
+		return urlstruct.Scan(ctx, &f.Floats, vs)
+	case "bools":
+		return urlstruct.Scan(ctx, &f.Bools, vs)
+	case "int8_s":
+		return urlstruct.Scan(ctx, &f.Int8s, vs)
+	case "times":
+		return urlstruct.Scan(ctx, &f.Times, vs)
+	case "statuses":
+		return urlstruct.Scan(ctx, &f.Statuses, vs)
+	case "levels":
+		return urlstruct.Scan(ctx, &f.Levels, vs)
+	case "point":
+		return urlstruct.Scan(ctx, &f.Point, vs)
+	case "data":
+		return urlstruct.DecodeParam(ctx, f, name, vs)
+	case "time":
+		return urlstruct.Scan(ctx, &f.Time, vs)
+	case "period":
+		return urlstruct.Scan(ctx, &f.Period, vs)
+	case "price":
+		return urlstruct.Scan(ctx, &f.Price, vs)
+	case "count":
+		return urlstruct.Scan(ctx, &f.Count, vs)
+	case "duration":
+		return urlstruct.Scan(ctx, &f.Duration, vs)
+	case "null_bool":
+		return urlstruct.Scan(ctx, &f.NullBool, vs)
+	case "null_int64":
+		return urlstruct.Scan(ctx, &f.NullInt64, vs)
+	case "null_float64":
+		return urlstruct.Scan(ctx, &f.NullFloat64, vs)
+	case "null_string":
+		return urlstruct.Scan(ctx, &f.NullString, vs)
+	case "null_time":
+		return urlstruct.Scan(ctx, &f.NullTime, vs)
+	case "null_int32":
+		return urlstruct.Scan(ctx, &f.NullInt32, vs)
+	case "null_int16":
+		return urlstruct.Scan(ctx, &f.NullInt16, vs)
+	case "null_byte":
+		return urlstruct.Scan(ctx, &f.NullByte, vs)
+	case "null_level":
+		return urlstruct.Scan(ctx, &f.NullLevel, vs)
+	case "null_times":
+		return urlstruct.Scan(ctx, &f.NullTimes, vs)
+	case "score":
+		return urlstruct.Scan(ctx, &f.Score, vs)
+	case "since":
+		return urlstruct.Scan(ctx, &f.Since, vs)
+	case "limit":
+		return urlstruct.Scan(ctx, &f.Limit, vs)
+	case "tags":
+		return urlstruct.Scan(ctx, &f.Tags, vs)
+	case "map":
+		return urlstruct.Scan(ctx, &f.Map, vs)
+	case "counts":
+		return urlstruct.Scan(ctx, &f.Counts, vs)
+	case "owners":
+		return urlstruct.Scan(ctx, &f.Owners, vs)
+	case "custom":
+		return urlstruct.Scan(ctx, &f.Custom, vs)
+	case "custom_ptr":
+		return urlstruct.Scan(ctx, &f.CustomPtr, vs)
+	case "token":
+		return urlstruct.DecodeParam(ctx, f, name, vs)
+	case "omit":
+		return urlstruct.Scan(ctx, &f.Omit, vs)
+	case "uuid":
+		return urlstruct.Scan(ctx, &f.Uuid, vs)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// Limits bound the input a single Unmarshal processes. Zero fields use the
+// defaults, which suit public-facing APIs, and negative fields disable
+// the limit. Unmarshal checks the limits before it calls the decoders
+// generated by urlstruct-gen, which check only MaxMapEntries themselves.
+type Limits struct {
+	// MaxParams is the number of params. The default is 1000.
+	MaxParams int
+	// MaxValues is the number of values per param. The default is 1000.
+	MaxValues int
+	// MaxMapEntries is the number of `name[key]` params of a map field.
+	// The default is 1000.
+	MaxMapEntries int
+	// MaxValueLen is the length of param names and values in bytes.
+	// The default is 64 KiB.
+	MaxValueLen int
+	// MaxDepth is the number of `[key]` parts in a param name.
+	// The default is 8.
+	MaxDepth int
+}
+
+var defaultLimits = Limits{}.resolve()
+
+func (l Limits) resolve() Limits {
+	return Limits{
+		MaxParams:     limit(l.MaxParams, 1000),
+		MaxValues:     limit(l.MaxValues, 1000),
+		MaxMapEntries: limit(l.MaxMapEntries, 1000),
+		MaxValueLen:   limit(l.MaxValueLen, 64<<10),
+		MaxDepth:      limit(l.MaxDepth, 8),
+	}
+}
+
+func limit(n, defaultLimit int) int {
+	switch {
+	case n == 0:
+		return defaultLimit
+	case n < 0:
+		return math.MaxInt
+	default:
+		return n
+	}
+}
+
+func (l *Limits) checkParams(values url.Values) error {
+	if len(values) > l.MaxParams {
+		return fmt.Errorf("urlstruct: %w", &LimitError{Limit: "MaxParams", Max: l.MaxParams})
+	}
+	return nil
+}
+
+// check checks all the values like structDecoder.Decode does while it
+// decodes them. MaxMapEntries is checked by the generated decoders, which
+// know the map fields.
+func (l *Limits) check(values url.Values) error {
+	if err := l.checkParams(values); err != nil {
+		return err
+	}
+	for name, vs := range values {
+		if err := l.checkParam(name, vs); err != nil {
+			return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkParam checks the param name and the values.
+func (l *Limits) checkParam(name string, values []string) error {
+	if len(values) > l.MaxValues {
+		return &LimitError{Limit: "MaxValues", Max: l.MaxValues}
+	}
+	if len(name) > l.MaxValueLen {
+		return &LimitError{Limit: "MaxValueLen", Max: l.MaxValueLen}
+	}
+	for _, s := range values {
+		if len(s) > l.MaxValueLen {
+			return &LimitError{Limit: "MaxValueLen", Max: l.MaxValueLen}
+		}
+	}
+	if strings.Count(name, "[") > l.MaxDepth {
+		return &LimitError{Limit: "MaxDepth", Max: l.MaxDepth}
+	}
+	return nil
+}
+
+type limitsKey struct{}
+
+// withLimits passes the limits to the generated decoders.
+func withLimits(ctx context.Context, limits Limits) context.Context {
+	if limits == defaultLimits {
+		return ctx
+	}
+	return context.WithValue(ctx, limitsKey{}, limits)
+}
+
+// CheckMapEntries returns an error if the map param with n entries exceeds
+// Limits.MaxMapEntries of the Decoder that calls UnmarshalValues, or the
+// default limit. It is used by the decoders generated with urlstruct-gen.
+func CheckMapEntries(ctx context.Context, name string, n int) error {
+	limits := defaultLimits
+	if ctx != nil {
+		if l, ok := ctx.Value(limitsKey{}).(Limits); ok {
+			limits = l
+		}
+	}
+	if n > limits.MaxMapEntries {
+		err := &LimitError{Limit: "MaxMapEntries", Max: limits.MaxMapEntries}
+		return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+	}
+	return nil
+}
+
+// LimitError is returned when the input exceeds one of the decoder Limits.
+type LimitError struct {
+	// Limit is the name of the Limits field, e.g. MaxParams.
+	Limit string
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case "MaxParams":
+		return fmt.Sprintf("too many params (limit %d)", e.Max)
+	case "MaxValues":
+		return fmt.Sprintf("too many values (limit %d)", e.Max)
+	case "MaxMapEntries":
+		return fmt.Sprintf("too many map entries (limit %d)", e.Max)
+	case "MaxValueLen":
+		return fmt.Sprintf("value is too long (limit %d bytes)", e.Max)
+	case "MaxDepth":
+		return fmt.Sprintf("param is nested too deeply (limit %d)", e.Max)
+	}
+	return fmt.Sprintf("%s exceeded (limit %d)", e.Limit, e.Max)
+}
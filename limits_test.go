@@ -0,0 +1,219 @@
+package urlstruct_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type LimitsFilter struct {
+	Query string
+	IDs   []int
+	Attrs map[string]string
+	Sub   struct {
+		Name string
+	}
+}
+
+// GeneratedLimitsFilter stands in for a decoder generated by urlstruct-gen.
+type GeneratedLimitsFilter struct {
+	Called bool
+}
+
+var _ urlstruct.GeneratedUnmarshaler = (*GeneratedLimitsFilter)(nil)
+
+func (f *GeneratedLimitsFilter) URLStructGenerated() {}
+
+func (f *GeneratedLimitsFilter) UnmarshalValues(ctx context.Context, values url.Values) error {
+	f.Called = true
+	return nil
+}
+
+// GrowingFilter adds params in BeforeDecode.
+type GrowingFilter struct {
+	Query string
+}
+
+func (f *GrowingFilter) BeforeDecode(ctx context.Context, values url.Values) error {
+	values.Set("x", "1")
+	values.Set("y", "1")
+	return nil
+}
+
+var _ = Describe("Limits", func() {
+	ctx := context.TODO()
+
+	d := &urlstruct.Decoder{
+		Limits: urlstruct.Limits{
+			MaxParams:     3,
+			MaxValues:     2,
+			MaxMapEntries: 2,
+			MaxValueLen:   8,
+			MaxDepth:      1,
+		},
+	}
+
+	It("rejects input over the limits", func() {
+		tests := []struct {
+			values url.Values
+			limit  string
+			err    string
+		}{
+			{
+				url.Values{"a": {""}, "b": {""}, "c": {""}, "d": {""}},
+				"MaxParams", `urlstruct: too many params (limit 3)`,
+			},
+			{
+				url.Values{"ids": {"1", "2", "3"}},
+				"MaxValues", `urlstruct: can't decode "ids": too many values (limit 2)`,
+			},
+			{
+				url.Values{"query": {"123456789"}},
+				"MaxValueLen", `urlstruct: can't decode "query": value is too long (limit 8 bytes)`,
+			},
+			{
+				url.Values{"long_name": {"1"}},
+				"MaxValueLen", `urlstruct: can't decode "long_name": value is too long (limit 8 bytes)`,
+			},
+			{
+				url.Values{"a[b][c]": {"1"}},
+				"MaxDepth", `urlstruct: can't decode "a[b][c]": param is nested too deeply (limit 1)`,
+			},
+			{
+				url.Values{"attrs[a]": {"1"}, "attrs[b]": {"2"}, "attrs[c]": {"3"}},
+				"MaxMapEntries", `urlstruct: can't decode "attrs": too many map entries (limit 2)`,
+			},
+		}
+		for _, test := range tests {
+			err := d.Unmarshal(ctx, test.values, new(LimitsFilter))
+			Expect(err).To(MatchError(test.err))
+
+			var limitErr *urlstruct.LimitError
+			Expect(errors.As(err, &limitErr)).To(BeTrue())
+			Expect(limitErr.Limit).To(Equal(test.limit))
+		}
+	})
+
+	It("checks the limits before calling generated decoders", func() {
+		tests := []struct {
+			values url.Values
+			err    string
+		}{
+			{
+				url.Values{"a": {""}, "b": {""}, "c": {""}, "d": {""}},
+				`urlstruct: too many params (limit 3)`,
+			},
+			{
+				url.Values{"ids": {"1", "2", "3"}},
+				`urlstruct: can't decode "ids": too many values (limit 2)`,
+			},
+			{
+				url.Values{"a[b][c]": {"1"}},
+				`urlstruct: can't decode "a[b][c]": param is nested too deeply (limit 1)`,
+			},
+		}
+		for _, test := range tests {
+			f := new(GeneratedLimitsFilter)
+			Expect(d.Unmarshal(ctx, test.values, f)).To(MatchError(test.err))
+			Expect(f.Called).To(BeFalse())
+		}
+
+		schema, err := urlstruct.NewSchema[GeneratedLimitsFilter]()
+		Expect(err).NotTo(HaveOccurred())
+		f := new(GeneratedLimitsFilter)
+		err = schema.Unmarshal(ctx, url.Values{"ids": make([]string, 1001)}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "ids": too many values (limit 1000)`))
+		Expect(f.Called).To(BeFalse())
+
+		// The generated decoders check MaxMapEntries themselves.
+		f = new(GeneratedLimitsFilter)
+		err = d.Unmarshal(ctx, url.Values{"attrs[a]": {"1"}, "attrs[b]": {"2"}, "attrs[c]": {"3"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Called).To(BeTrue())
+	})
+
+	It("counts only the entries of map fields", func() {
+		f := new(LimitsFilter)
+		err := d.Unmarshal(ctx, url.Values{"x[a]": {"1"}, "x[b]": {"2"}, "x[c]": {"3"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("checks MaxParams on the values returned by BeforeDecode", func() {
+		err := d.Unmarshal(ctx, url.Values{"query": {"a"}, "z": {""}}, new(GrowingFilter))
+		Expect(err).To(MatchError(`urlstruct: too many params (limit 3)`))
+
+		f := new(GrowingFilter)
+		err = d.Unmarshal(ctx, url.Values{"query": {"a"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Query).To(Equal("a"))
+	})
+
+	It("decodes input within the limits", func() {
+		f := new(LimitsFilter)
+		err := d.Unmarshal(ctx, url.Values{
+			"ids":      {"1", "2"},
+			"attrs[a]": {"12345678"},
+			"attrs[b]": {"2"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.IDs).To(Equal([]int{1, 2}))
+		Expect(f.Attrs).To(Equal(map[string]string{"a": "12345678", "b": "2"}))
+	})
+
+	It("has defaults and can be disabled", func() {
+		values := make(url.Values)
+		for i := 0; i < 1001; i++ {
+			values.Set("p"+strconv.Itoa(i), "")
+		}
+		err := urlstruct.Unmarshal(ctx, values, new(LimitsFilter))
+		Expect(err).To(MatchError(`urlstruct: too many params (limit 1000)`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"query": {strings.Repeat("x", 64<<10+1)}}, new(LimitsFilter))
+		Expect(err).To(MatchError(ContainSubstring("value is too long (limit 65536 bytes)")))
+
+		unlimited := &urlstruct.Decoder{
+			Limits: urlstruct.Limits{MaxParams: -1, MaxMapEntries: -1},
+		}
+		Expect(unlimited.Unmarshal(ctx, values, new(LimitsFilter))).To(Succeed())
+	})
+})
+
+// FuzzLimits checks that decoding with small limits never panics and that
+// the decoded slices and maps stay within the limits.
+func FuzzLimits(f *testing.F) {
+	f.Add("query=a&ids=1&ids=2&attrs[a]=b&sub[name]=c")
+	f.Add("attrs[a]=1&attrs[b]=2&attrs[c]=3&a[b][c][d]=1")
+	f.Add(strings.Repeat("ids=1&", 20))
+
+	d := &urlstruct.Decoder{
+		Limits: urlstruct.Limits{
+			MaxParams:     8,
+			MaxValues:     4,
+			MaxMapEntries: 4,
+			MaxValueLen:   32,
+			MaxDepth:      2,
+		},
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return
+		}
+
+		filter := new(LimitsFilter)
+		if err := d.Unmarshal(context.Background(), values, filter); err != nil {
+			return
+		}
+		if len(filter.IDs) > 4 || len(filter.Attrs) > 4 || len(filter.Query) > 32 {
+			t.Fatalf("decoded %+v over the limits", filter)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package urlstruct
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// fieldName is an alias or a case-folded name of a field. When a request
+// has several names of the same field, the name with the lowest rank wins.
+type fieldName struct {
+	field *Field
+	rank  int
+	name  string // the name in the request; set by winningNames
+}
+
+// initNames indexes the field aliases and, if ignoreCase is set, the
+// case-folded names. The field name comes first, then the aliases in the
+// declared order, then the same names matched case-insensitively.
+func (s *StructInfo) initNames(ignoreCase bool) {
+	s.ignoreCase = ignoreCase
+
+	for _, f := range s.fields {
+		if s.fieldMap[f.Name] != f {
+			continue
+		}
+		for i, alias := range f.Aliases {
+			if other, ok := s.fieldMap[alias]; ok && other != f {
+				s.conflict(alias, f, other, "")
+				continue
+			}
+			if prev, ok := s.aliases[alias]; ok {
+				if prev.field != f {
+					s.conflict(alias, f, prev.field, "")
+				}
+				continue
+			}
+			if s.aliases == nil {
+				s.aliases = make(map[string]fieldName)
+			}
+			s.aliases[alias] = fieldName{field: f, rank: i + 1}
+		}
+	}
+
+	if !ignoreCase {
+		return
+	}
+
+	s.folded = make(map[string]fieldName, len(s.fieldMap))
+	for _, f := range s.fields {
+		if s.fieldMap[f.Name] != f {
+			continue
+		}
+		names := append([]string{f.Name}, f.Aliases...)
+		for i, name := range names {
+			key := strings.ToLower(name)
+			if prev, ok := s.folded[key]; ok {
+				if prev.field != f {
+					s.conflict(name, f, prev.field, " ignoring case")
+				}
+				continue
+			}
+			s.folded[key] = fieldName{field: f, rank: len(names) + i}
+		}
+	}
+}
+
+func (s *StructInfo) conflict(name string, f, other *Field, suffix string) {
+	s.conflicts = append(s.conflicts, fmt.Sprintf(
+		"name %q is used by both %s and %s%s", name, other.Name, f.Name, suffix))
+}
+
+func (s *StructInfo) hasConflicts() bool {
+	if len(s.conflicts) > 0 {
+		return true
+	}
+	for _, nested := range s.structs {
+		if nested.sinfo.hasConflicts() {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the field with the name or alias and the rank of the name.
+func (s *StructInfo) lookup(name string) (*Field, int) {
+	if f, ok := s.fieldMap[name]; ok {
+		return f, 0
+	}
+	if n, ok := s.aliases[name]; ok {
+		return n.field, n.rank
+	}
+	if s.ignoreCase {
+		if n, ok := s.folded[strings.ToLower(name)]; ok {
+			return n.field, n.rank
+		}
+	}
+	return nil, 0
+}
+
+// winningNames returns the name that takes precedence for each field that
+// the request has several names of: the name with the lowest rank or, for
+// equal ranks, the first name in sort order. It returns nil if the request
+// uses only the field names.
+func (s *StructInfo) winningNames(values url.Values) map[*Field]fieldName {
+	hasOtherNames := false
+	for name := range values {
+		if _, rank := s.lookup(trimParam(name)); rank > 0 {
+			hasOtherNames = true
+			break
+		}
+	}
+	if !hasOtherNames {
+		return nil
+	}
+
+	winners := make(map[*Field]fieldName)
+	for name := range values {
+		name = trimParam(name)
+		f, rank := s.lookup(name)
+		if f == nil {
+			continue
+		}
+		if w, ok := winners[f]; ok && (w.rank < rank || w.rank == rank && w.name <= name) {
+			continue
+		}
+		winners[f] = fieldName{field: f, rank: rank, name: name}
+	}
+	return winners
+}
+
+// shadowed reports whether the request has another name of the same field
+// that takes precedence over the name.
+func (d structDecoder) shadowed(winners map[*Field]fieldName, name string) bool {
+	f, rank := d.sinfo.lookup(name)
+	if f == nil || rank == 0 {
+		return false
+	}
+	return winners[f].name != name
+}
@@ -0,0 +1,137 @@
+package urlstruct_test
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type AliasFilter struct {
+	AuthorID int    `urlstruct:"author_id,alias:authorId|author"`
+	Title    string `urlstruct:",alias:q"`
+	Tags     []string
+}
+
+type ConflictFilter struct {
+	AuthorID int `urlstruct:",alias:author"`
+	Author   string
+}
+
+type CaseConflictFilter struct {
+	Name     string
+	FullName string `urlstruct:",alias:NAME"`
+}
+
+type InvalidAliasFilter struct {
+	AuthorID int `urlstruct:",alias:author[]"`
+}
+
+var _ = Describe("Param names", func() {
+	ctx := context.TODO()
+
+	It("decodes aliases", func() {
+		f := new(AliasFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"authorId": {"1"}, "q": {"go"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.AuthorID).To(Equal(1))
+		Expect(f.Title).To(Equal("go"))
+
+		f = new(AliasFilter)
+		err = urlstruct.Unmarshal(ctx, url.Values{":author": {"2"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.AuthorID).To(Equal(2))
+
+		sinfo := urlstruct.DescribeStruct(reflect.TypeOf(AliasFilter{}))
+		Expect(sinfo.Field("author").Name).To(Equal("author_id"))
+		Expect(sinfo.Field("author_id").Aliases).To(Equal([]string{"authorId", "author"}))
+	})
+
+	It("prefers the name and then the aliases in the declared order", func() {
+		for i := 0; i < 10; i++ {
+			f := new(AliasFilter)
+			err := urlstruct.Unmarshal(ctx, url.Values{
+				"author":    {"3"},
+				"authorId":  {"2"},
+				"author_id": {"1"},
+			}, f)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.AuthorID).To(Equal(1))
+
+			f = new(AliasFilter)
+			err = urlstruct.Unmarshal(ctx, url.Values{
+				"author":   {"3"},
+				"authorId": {"2"},
+			}, f)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.AuthorID).To(Equal(2))
+		}
+	})
+
+	It("does not decode shadowed values", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"author_id": {"1"},
+			"author":    {"invalid"},
+		}, new(AliasFilter))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("matches names case-insensitively", func() {
+		d := &urlstruct.Decoder{IgnoreCase: true}
+
+		f := new(AliasFilter)
+		err := d.Unmarshal(ctx, url.Values{"AuthorID": {"1"}, "TAGS": {"a", "b"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.AuthorID).To(Equal(1))
+		Expect(f.Tags).To(Equal([]string{"a", "b"}))
+
+		for i := 0; i < 10; i++ {
+			f := new(AliasFilter)
+			err := d.Unmarshal(ctx, url.Values{
+				"AUTHOR_ID": {"1"},
+				"authorId":  {"2"},
+				"Title":     {"b"},
+				"TITLE":     {"a"},
+			}, f)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.AuthorID).To(Equal(2))
+			Expect(f.Title).To(Equal("a"))
+		}
+
+		f = new(AliasFilter)
+		err = urlstruct.Unmarshal(ctx, url.Values{"AuthorID": {"1"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.AuthorID).To(Equal(0))
+	})
+
+	It("detects conflicting names", func() {
+		_, err := new(urlstruct.Decoder).DescribeStruct(reflect.TypeOf(ConflictFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.ConflictFilter: ` +
+			`name "author" is used by both author and author_id`))
+		Expect(func() {
+			urlstruct.DescribeStruct(reflect.TypeOf(ConflictFilter{}))
+		}).To(Panic())
+
+		err = urlstruct.DecodeParam(ctx, new(ConflictFilter), "author", []string{"x"})
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.ConflictFilter: ` +
+			`name "author" is used by both author and author_id`))
+
+		_, err = new(urlstruct.Decoder).DescribeStruct(reflect.TypeOf(CaseConflictFilter{}))
+		Expect(err).NotTo(HaveOccurred())
+
+		d := &urlstruct.Decoder{IgnoreCase: true}
+		_, err = d.DescribeStruct(reflect.TypeOf(CaseConflictFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.CaseConflictFilter: ` +
+			`name "NAME" is used by both name and full_name ignoring case`))
+	})
+
+	It("rejects invalid aliases", func() {
+		err := urlstruct.Validate(reflect.TypeOf(InvalidAliasFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.InvalidAliasFilter: ` +
+			`field AuthorID has invalid alias "author[]"`))
+	})
+})
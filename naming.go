@@ -0,0 +1,34 @@
+package urlstruct
+
+import (
+	"github.com/codemodus/kace"
+)
+
+// SnakeCase converts names like AuthorID to author_id. It is the default
+// naming strategy.
+func SnakeCase(name string) string {
+	return kace.Snake(name)
+}
+
+// CamelCase converts names like AuthorID and author_id to authorID.
+func CamelCase(name string) string {
+	return kace.Camel(name)
+}
+
+// KebabCase converts names like AuthorID to author-id.
+func KebabCase(name string) string {
+	return kace.Kebab(name)
+}
+
+// ExactCase uses the field and tag names as is.
+func ExactCase(name string) string {
+	return name
+}
+
+// paramName converts the field or tag name using the naming strategy.
+func (d *Decoder) paramName(name string) string {
+	if d.Naming != nil {
+		return d.Naming(name)
+	}
+	return SnakeCase(name)
+}
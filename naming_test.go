@@ -0,0 +1,72 @@
+package urlstruct_test
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type NamingPage struct {
+	PageSize int
+}
+
+type NamingFilter struct {
+	AuthorID  int
+	SortOrder string `urlstruct:"sort_order"`
+	Legacy    string `urlstruct:",alias:legacy_name"`
+	Page      NamingPage
+}
+
+var _ = Describe("Naming", func() {
+	ctx := context.TODO()
+
+	names := func(d *urlstruct.Decoder) []string {
+		sinfo, err := d.DescribeStruct(reflect.TypeOf(NamingFilter{}))
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, f := range sinfo.Fields() {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	It("uses snake case by default", func() {
+		Expect(names(new(urlstruct.Decoder))).To(Equal([]string{"author_id", "sort_order", "legacy"}))
+	})
+
+	It("supports the predefined strategies", func() {
+		Expect(names(&urlstruct.Decoder{Naming: urlstruct.CamelCase})).
+			To(Equal([]string{"authorID", "sortOrder", "legacy"}))
+		Expect(names(&urlstruct.Decoder{Naming: urlstruct.KebabCase})).
+			To(Equal([]string{"author-id", "sort-order", "legacy"}))
+		Expect(names(&urlstruct.Decoder{Naming: urlstruct.ExactCase})).
+			To(Equal([]string{"AuthorID", "sort_order", "Legacy"}))
+		Expect(names(&urlstruct.Decoder{Naming: strings.ToUpper})).
+			To(Equal([]string{"AUTHORID", "SORT_ORDER", "LEGACY"}))
+	})
+
+	It("applies the strategy to nested structs and keeps aliases", func() {
+		d := &urlstruct.Decoder{Naming: urlstruct.CamelCase}
+
+		f := new(NamingFilter)
+		err := d.Unmarshal(ctx, url.Values{
+			"authorID":       {"1"},
+			"sortOrder":      {"desc"},
+			"legacy_name":    {"x"},
+			"page[pageSize]": {"10"},
+			"author_id":      {"2"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.AuthorID).To(Equal(1))
+		Expect(f.SortOrder).To(Equal("desc"))
+		Expect(f.Legacy).To(Equal("x"))
+		Expect(f.Page.PageSize).To(Equal(10))
+	})
+})
@@ -0,0 +1,176 @@
+package urlstruct
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+// Optional is a param that can be absent, null or have a value. Null is
+// the Decoder.Null value, which is "null" by default, or an empty value
+// unless T is a string, a bool or a type that decodes itself. Use pointers or sql.Null* types when
+// there is no need to tell absent and null params apart.
+type Optional[T any] struct {
+	Value T `urlstruct:"-"`
+
+	// Present reports whether the param is present.
+	Present bool `urlstruct:"-"`
+	// Null reports whether the param is null.
+	Null bool `urlstruct:"-"`
+}
+
+// Get returns the value and reports whether the param is present and is
+// not null.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Present && !o.Null
+}
+
+func (Optional[T]) isOptional() {}
+
+type optional interface {
+	isOptional()
+}
+
+var optionalType = reflect.TypeOf((*optional)(nil)).Elem()
+
+// nullable returns the type of the value held by the pointer, Optional or
+// sql.Null* type.
+func nullable(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem(), true
+	}
+	if typ.Kind() == reflect.Struct && (typ.Implements(optionalType) || isNullStruct(typ)) {
+		return typ.Field(0).Type, true
+	}
+	return nil, false
+}
+
+// nullChecker returns a func that reports whether the value of the type
+// decodes as NULL.
+func (d *Decoder) nullChecker(typ reflect.Type, tag *tagparser.Tag) func(s string) bool {
+	null := d.Null
+	if null == "" {
+		null = "null"
+	}
+	emptyIsNull := !hasEmptyValue(typ) && !decodesItself(typ, tag)
+	return func(s string) bool {
+		return s == null || s == "" && emptyIsNull
+	}
+}
+
+// hasEmptyValue reports whether the type can be decoded from an empty value:
+// strings and []byte decode to empty values and bools to true. The types
+// that decode themselves are checked separately.
+func hasEmptyValue(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// decodesItself reports whether the scanner of the type uses its own
+// method. Times are parsed by the scanner even though they implement
+// encoding.TextUnmarshaler.
+func decodesItself(typ reflect.Type, tag *tagparser.Tag) bool {
+	return typ != timeType && typ != timeRangeType && hasUnmarshaler(typ, tag)
+}
+
+// ptrScanner decodes the value into a new pointer, which replaces the field
+// on success. Null values set the pointer to nil.
+func (d *Decoder) ptrScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	scan := d.scanner(typ.Elem(), tag)
+	if scan == nil {
+		return nil
+	}
+
+	isNull := d.nullChecker(typ.Elem(), tag)
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		if isNull(values[0]) {
+			v.Set(reflect.Zero(typ))
+			return nil
+		}
+
+		p := reflect.New(typ.Elem())
+		if err := scan(ctx, p.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+}
+
+func (d *Decoder) optionalScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	valueType := typ.Field(0).Type
+	scan := d.fieldScanner(valueType, tag)
+	if scan == nil {
+		return nil
+	}
+
+	isNull := d.nullChecker(valueType, tag)
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		if len(values) == 1 && isNull(values[0]) {
+			v.Set(reflect.Zero(typ))
+			v.Field(1).SetBool(true)
+			v.Field(2).SetBool(true)
+			return nil
+		}
+
+		value := reflect.New(valueType).Elem()
+		if err := scan(ctx, value, values); err != nil {
+			return err
+		}
+		v.Field(0).Set(value)
+		v.Field(1).SetBool(true)
+		v.Field(2).SetBool(false)
+		return nil
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var sqlScannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// isNullStruct reports whether the type is like the sql.Null* types and
+// sql.Null[T]: a struct that implements sql.Scanner and has a value field
+// followed by the Valid field.
+func isNullStruct(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 2 {
+		return false
+	}
+	valid := typ.Field(1)
+	return typ.Field(0).IsExported() &&
+		valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool &&
+		reflect.PtrTo(typ).Implements(sqlScannerType)
+}
+
+// nullStructScanner decodes the value field of the sql.Null* type with
+// the scanner of the field type, so the tag options apply to the value.
+// Null values reset the struct.
+func (d *Decoder) nullStructScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	valueType := typ.Field(0).Type
+	scan := d.scanner(valueType, tag)
+	if scan == nil {
+		return nil
+	}
+
+	isNull := d.nullChecker(valueType, tag)
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		if isNull(values[0]) {
+			v.Set(reflect.Zero(typ))
+			return nil
+		}
+
+		value := reflect.New(typ).Elem()
+		if err := scan(ctx, value.Field(0), values); err != nil {
+			return err
+		}
+		value.Field(1).SetBool(true)
+		v.Set(value)
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+//go:build go1.22
+
+package urlstruct_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type GenericNullFilter struct {
+	Int    sql.Null[int]
+	Time   sql.Null[time.Time]
+	Query  sql.Null[string]
+	Status []sql.Null[Status]
+}
+
+var _ = Describe("sql.Null[T]", func() {
+	ctx := context.TODO()
+
+	It("decodes values and nulls", func() {
+		f := new(GenericNullFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"int":    {"1"},
+			"time":   {""},
+			"query":  {""},
+			"status": {"active", "null"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Int).To(Equal(sql.Null[int]{V: 1, Valid: true}))
+		Expect(f.Time.Valid).To(BeFalse())
+		Expect(f.Query).To(Equal(sql.Null[string]{Valid: true}))
+		Expect(f.Status).To(Equal([]sql.Null[Status]{{V: "active", Valid: true}, {}}))
+	})
+})
@@ -0,0 +1,279 @@
+package urlstruct_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type NullFilter struct {
+	NullInt64   sql.NullInt64
+	NullFloat64 sql.NullFloat64
+	NullString  sql.NullString
+	NullBool    sql.NullBool
+	NullInts    []sql.NullInt64
+
+	Int    *int
+	String *string
+	Time   *time.Time
+	Custom *CustomField
+	Code   *Code
+
+	Limit urlstruct.Optional[int]
+	Query urlstruct.Optional[string]
+	Flag  urlstruct.Optional[bool]
+	IDs   urlstruct.Optional[[]int]
+
+	CustomOpt urlstruct.Optional[CustomField]
+	Single    urlstruct.Optional[int] `urlstruct:",repeat:error"`
+}
+
+// Code rejects empty and null values.
+type Code string
+
+func (c *Code) UnmarshalText(text []byte) error {
+	if len(text) == 0 || string(text) == "null" {
+		return errors.New("code is required")
+	}
+	*c = Code(text)
+	return nil
+}
+
+func newString(s string) *string {
+	return &s
+}
+
+func newInt(n int) *int {
+	return &n
+}
+
+var _ = Describe("Nulls", func() {
+	ctx := context.TODO()
+
+	It("decodes null", func() {
+		f := &NullFilter{
+			Int:    newInt(1),
+			String: newString("s"),
+			Custom: &CustomField{S: "s"},
+		}
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"null_int64":   {"null"},
+			"null_float64": {"null"},
+			"null_string":  {"null"},
+			"null_bool":    {"null"},
+			"null_ints":    {"1", "null"},
+			"int":          {"null"},
+			"string":       {"null"},
+			"time":         {"null"},
+			"custom":       {"null"},
+			"limit":        {"null"},
+			"query":        {"null"},
+			"ids":          {"null"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+
+		nullLimit := urlstruct.Optional[int]{Present: true, Null: true}
+		Expect(f).To(Equal(&NullFilter{
+			NullInts: []sql.NullInt64{{Int64: 1, Valid: true}, {}},
+			Limit:    nullLimit,
+			Query:    urlstruct.Optional[string]{Present: true, Null: true},
+			IDs:      urlstruct.Optional[[]int]{Present: true, Null: true},
+		}))
+
+		_, ok := f.Limit.Get()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("decodes empty values as null unless the type is a string or a bool", func() {
+		f := &NullFilter{Int: newInt(1)}
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"null_int64":   {""},
+			"null_float64": {""},
+			"null_string":  {""},
+			"null_bool":    {""},
+			"int":          {""},
+			"string":       {""},
+			"time":         {""},
+			"limit":        {""},
+			"query":        {""},
+			"flag":         {""},
+			"ids":          {""},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(f.NullInt64.Valid).To(BeFalse())
+		Expect(f.NullFloat64.Valid).To(BeFalse())
+		Expect(f.NullString).To(Equal(sql.NullString{Valid: true}))
+		Expect(f.NullBool).To(Equal(sql.NullBool{Bool: true, Valid: true}))
+		Expect(f.Int).To(BeNil())
+		Expect(f.String).To(Equal(newString("")))
+		Expect(f.Time).To(BeNil())
+		Expect(f.Limit).To(Equal(urlstruct.Optional[int]{Present: true, Null: true}))
+		Expect(f.Query).To(Equal(urlstruct.Optional[string]{Present: true}))
+		Expect(f.Flag).To(Equal(urlstruct.Optional[bool]{Value: true, Present: true}))
+		Expect(f.IDs).To(Equal(urlstruct.Optional[[]int]{Present: true, Null: true}))
+	})
+
+	It("lets the types that decode themselves decode empty values", func() {
+		code := Code("x")
+		f := &NullFilter{Code: &code}
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"custom":     {""},
+			"custom_opt": {""},
+			"code":       {"null"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Custom).To(Equal(&CustomField{}))
+		Expect(f.CustomOpt).To(Equal(urlstruct.Optional[CustomField]{Present: true}))
+		Expect(f.Code).To(BeNil())
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"code": {""}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "code": code is required`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"code": {"a"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*f.Code).To(Equal(Code("a")))
+	})
+
+	It("decodes values", func() {
+		f := new(NullFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"int":    {"1"},
+			"string": {"null value"},
+			"time":   {"2024-03-01"},
+			"custom": {"c"},
+			"limit":  {"10"},
+			"flag":   {"false"},
+			"ids":    {"1", "2"},
+			"single": {"1"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(f.Int).To(Equal(newInt(1)))
+		Expect(f.String).To(Equal(newString("null value")))
+		Expect(*f.Time).To(Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(f.Custom).To(Equal(&CustomField{S: "c"}))
+		Expect(f.Flag).To(Equal(urlstruct.Optional[bool]{Present: true}))
+		Expect(f.IDs.Value).To(Equal([]int{1, 2}))
+		Expect(f.Single.Value).To(Equal(1))
+
+		limit, ok := f.Limit.Get()
+		Expect(ok).To(BeTrue())
+		Expect(limit).To(Equal(10))
+		Expect(f.Query.Present).To(BeFalse())
+	})
+
+	It("keeps the field on errors", func() {
+		f := &NullFilter{
+			Int:   newInt(1),
+			Limit: urlstruct.Optional[int]{Value: 1, Present: true},
+		}
+		err := urlstruct.Unmarshal(ctx, url.Values{"int": {"x"}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "int": ` +
+			`strconv.ParseInt: parsing "x": invalid syntax`))
+		Expect(f.Int).To(Equal(newInt(1)))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"limit": {"x"}}, f)
+		Expect(err).To(HaveOccurred())
+		Expect(f.Limit).To(Equal(urlstruct.Optional[int]{Value: 1, Present: true}))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"single": {"1", "2"}}, f)
+		Expect(errors.Is(err, urlstruct.ErrRepeated)).To(BeTrue())
+	})
+
+	It("uses Decoder.Null", func() {
+		d := &urlstruct.Decoder{Null: "nil"}
+
+		f := new(NullFilter)
+		err := d.Unmarshal(ctx, url.Values{
+			"null_string": {"null"},
+			"null_int64":  {"nil"},
+			"limit":       {"nil"},
+			"query":       {"null"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.NullString).To(Equal(sql.NullString{String: "null", Valid: true}))
+		Expect(f.NullInt64.Valid).To(BeFalse())
+		Expect(f.Limit.Null).To(BeTrue())
+		Expect(f.Query.Value).To(Equal("null"))
+	})
+})
+
+// GenericNull is shaped like sql.Null[T].
+type GenericNull[T any] struct {
+	V     T
+	Valid bool
+}
+
+func (n *GenericNull[T]) Scan(src interface{}) error {
+	n.V, n.Valid = src.(T)
+	return nil
+}
+
+type NullTypesFilter struct {
+	Time   sql.NullTime `urlstruct:",unit:ms"`
+	Int32  sql.NullInt32
+	Int16  sql.NullInt16
+	Byte   sql.NullByte
+	Color  GenericNull[Color]
+	Times  []sql.NullTime
+	Colors []GenericNull[Color]
+	Ptr    *sql.NullInt32
+}
+
+var _ = Describe("sql.Null types", func() {
+	ctx := context.TODO()
+
+	It("decodes the value field with its scanner", func() {
+		f := new(NullTypesFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"time":   {"1000"},
+			"int32":  {"-2147483648"},
+			"int16":  {"null"},
+			"byte":   {"255"},
+			"color":  {"red"},
+			"times":  {"2024-03-01", "", "null"},
+			"colors": {"green", "null"},
+			"ptr":    {"1"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(f.Time).To(Equal(sql.NullTime{Time: time.UnixMilli(1000), Valid: true}))
+		Expect(f.Int32).To(Equal(sql.NullInt32{Int32: -2147483648, Valid: true}))
+		Expect(f.Int16.Valid).To(BeFalse())
+		Expect(f.Byte).To(Equal(sql.NullByte{Byte: 255, Valid: true}))
+		Expect(f.Color).To(Equal(GenericNull[Color]{V: "red", Valid: true}))
+		Expect(f.Times).To(Equal([]sql.NullTime{
+			{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true}, {}, {},
+		}))
+		Expect(f.Colors).To(Equal([]GenericNull[Color]{{V: "green", Valid: true}, {}}))
+		Expect(f.Ptr).To(Equal(&sql.NullInt32{Int32: 1, Valid: true}))
+	})
+
+	It("returns the errors of the value scanner", func() {
+		tests := []struct {
+			values url.Values
+			err    string
+		}{
+			{url.Values{"int32": {"2147483648"}}, `urlstruct: can't decode "int32": ` +
+				`value 2147483648 is out of range for int32`},
+			{url.Values{"byte": {"-1"}}, `urlstruct: can't decode "byte": ` +
+				`value -1 is out of range for uint8`},
+			{url.Values{"color": {"pink"}}, `urlstruct: can't decode "color": ` +
+				`value "pink" is not one of red, green`},
+		}
+		for _, test := range tests {
+			f := &NullTypesFilter{Int32: sql.NullInt32{Int32: 1, Valid: true}}
+			err := urlstruct.Unmarshal(ctx, test.values, f)
+			Expect(err).To(MatchError(test.err))
+			Expect(f.Int32).To(Equal(sql.NullInt32{Int32: 1, Valid: true}))
+		}
+	})
+})
@@ -28,16 +28,19 @@ func NewPager(values url.Values) *Pager {
 
 var _ Unmarshaler = (*Pager)(nil)
 
+// UnmarshalValues decodes the limit and page params. Repeated params are
+// handled with the policy from RepeatPolicyFromContext.
 func (p *Pager) UnmarshalValues(ctx context.Context, values url.Values) error {
 	vs := Values(values)
+	policy := RepeatPolicyFromContext(ctx)
 
-	limit, err := vs.Int("limit")
+	limit, err := vs.IntValue("limit", policy)
 	if err != nil {
 		return err
 	}
 	p.Limit = limit
 
-	page, err := vs.Int("page")
+	page, err := vs.IntValue("page", policy)
 	if err != nil {
 		return err
 	}
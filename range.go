@@ -0,0 +1,216 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Number is the constraint for the Range type parameter.
+type Number interface {
+	signed | unsigned | float
+}
+
+// Range is a numeric range decoded from values like 10..100, 10.. or
+// ..100, from the interval notation like [10,100) or (0,1], or from params
+// like price[gte]=10&price[lte]=100, where gt and lt exclude the bound.
+// A single value like 10 decodes to the range containing only the value.
+// Decoding sets only the given bounds and fails if Min is greater than Max.
+type Range[T Number] struct {
+	Min T `urlstruct:"-"`
+	Max T `urlstruct:"-"`
+
+	// HasMin and HasMax report whether the range is bounded on that side.
+	HasMin bool `urlstruct:"-"`
+	HasMax bool `urlstruct:"-"`
+
+	// MinExclusive and MaxExclusive report whether the bound itself is
+	// excluded from the range.
+	MinExclusive bool `urlstruct:"-"`
+	MaxExclusive bool `urlstruct:"-"`
+}
+
+var _ ParamUnmarshaler = (*Range[int])(nil)
+
+// IsZero reports whether the range is unbounded on both sides.
+func (r Range[T]) IsZero() bool {
+	return !r.HasMin && !r.HasMax
+}
+
+// Contains reports whether the value is in the range.
+func (r Range[T]) Contains(v T) bool {
+	if r.HasMin && (v < r.Min || r.MinExclusive && v == r.Min) {
+		return false
+	}
+	if r.HasMax && (v > r.Max || r.MaxExclusive && v == r.Max) {
+		return false
+	}
+	return true
+}
+
+// MinOp returns the SQL operator for the lower bound: >= or >.
+func (r Range[T]) MinOp() string {
+	if r.MinExclusive {
+		return ">"
+	}
+	return ">="
+}
+
+// MaxOp returns the SQL operator for the upper bound: <= or <.
+func (r Range[T]) MaxOp() string {
+	if r.MaxExclusive {
+		return "<"
+	}
+	return "<="
+}
+
+// String returns the range in the format accepted by UnmarshalText.
+func (r Range[T]) String() string {
+	var min, max string
+	if r.HasMin {
+		min = fmt.Sprint(r.Min)
+	}
+	if r.HasMax {
+		max = fmt.Sprint(r.Max)
+	}
+
+	if !r.MinExclusive && !r.MaxExclusive {
+		return min + ".." + max
+	}
+
+	open, close := "[", "]"
+	if r.MinExclusive {
+		open = "("
+	}
+	if r.MaxExclusive {
+		close = ")"
+	}
+	return open + min + "," + max + close
+}
+
+func (r Range[T]) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Range[T]) UnmarshalText(b []byte) error {
+	s := string(b)
+
+	var min, max string
+	var minExclusive, maxExclusive bool
+	if isInterval(s) {
+		var ok bool
+		min, max, ok = strings.Cut(s[1:len(s)-1], ",")
+		if !ok {
+			return fmt.Errorf("invalid range %q", s)
+		}
+		minExclusive = s[0] == '('
+		maxExclusive = s[len(s)-1] == ')'
+	} else if start, end, ok := strings.Cut(s, ".."); ok {
+		min, max = start, end
+	} else {
+		min, max = s, s
+	}
+	if min == "" && max == "" {
+		return fmt.Errorf("invalid range %q", s)
+	}
+
+	if min != "" {
+		if err := r.setMin(min, minExclusive); err != nil {
+			return err
+		}
+	}
+	if max != "" {
+		if err := r.setMax(max, maxExclusive); err != nil {
+			return err
+		}
+	}
+	return r.validate()
+}
+
+func isInterval(s string) bool {
+	return len(s) >= 2 &&
+		(s[0] == '[' || s[0] == '(') &&
+		(s[len(s)-1] == ']' || s[len(s)-1] == ')')
+}
+
+// UnmarshalParam decodes the gte, gt, lte and lt params.
+func (r *Range[T]) UnmarshalParam(ctx context.Context, name string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	var err error
+	switch name {
+	case "gte":
+		err = r.setMin(values[0], false)
+	case "gt":
+		err = r.setMin(values[0], true)
+	case "lte":
+		err = r.setMax(values[0], false)
+	case "lt":
+		err = r.setMax(values[0], true)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.validate()
+}
+
+func (r *Range[T]) setMin(s string, exclusive bool) error {
+	n, err := parseNumber[T](s)
+	if err != nil {
+		return err
+	}
+	r.Min, r.HasMin, r.MinExclusive = n, true, exclusive
+	return nil
+}
+
+func (r *Range[T]) setMax(s string, exclusive bool) error {
+	n, err := parseNumber[T](s)
+	if err != nil {
+		return err
+	}
+	r.Max, r.HasMax, r.MaxExclusive = n, true, exclusive
+	return nil
+}
+
+func (r *Range[T]) validate() error {
+	if r.HasMin && r.HasMax && r.Min > r.Max {
+		return fmt.Errorf("range min %v is greater than max %v", r.Min, r.Max)
+	}
+	return nil
+}
+
+func parseNumber[T Number](s string) (T, error) {
+	var n T
+	v := reflect.ValueOf(&n).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := parseInt(s, v.Type(), false)
+		if err != nil {
+			return n, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := parseUint(s, v.Type(), false)
+		if err != nil {
+			return n, err
+		}
+		v.SetUint(u)
+	default:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return n, err
+		}
+		if math.IsNaN(f) {
+			return n, fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	}
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package urlstruct_test
+
+import (
+	"context"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type RangeFilter struct {
+	Price  urlstruct.Range[float64]
+	Count  urlstruct.Range[int]
+	Age    urlstruct.Range[uint8]
+	Scores []urlstruct.Range[int]
+}
+
+var _ = Describe("Range", func() {
+	ctx := context.TODO()
+
+	It("decodes ranges", func() {
+		tests := []struct {
+			value  string
+			wanted urlstruct.Range[int]
+		}{
+			{"10..100", urlstruct.Range[int]{Min: 10, Max: 100, HasMin: true, HasMax: true}},
+			{"10..", urlstruct.Range[int]{Min: 10, HasMin: true}},
+			{"..100", urlstruct.Range[int]{Max: 100, HasMax: true}},
+			{"-10..-5", urlstruct.Range[int]{Min: -10, Max: -5, HasMin: true, HasMax: true}},
+			{"7", urlstruct.Range[int]{Min: 7, Max: 7, HasMin: true, HasMax: true}},
+			{"[10,100)", urlstruct.Range[int]{
+				Min: 10, Max: 100, HasMin: true, HasMax: true, MaxExclusive: true,
+			}},
+			{"(10,]", urlstruct.Range[int]{Min: 10, HasMin: true, MinExclusive: true}},
+		}
+		for _, test := range tests {
+			f := new(RangeFilter)
+			err := urlstruct.Unmarshal(ctx, url.Values{"count": {test.value}}, f)
+			Expect(err).NotTo(HaveOccurred(), test.value)
+			Expect(f.Count).To(Equal(test.wanted), test.value)
+		}
+	})
+
+	It("decodes bracket params", func() {
+		f := new(RangeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"price[gte]": {"9.5"},
+			"price[lt]":  {"100"},
+			"age[gt]":    {"17"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Price).To(Equal(urlstruct.Range[float64]{
+			Min: 9.5, Max: 100, HasMin: true, HasMax: true, MaxExclusive: true,
+		}))
+		Expect(f.Price.MinOp()).To(Equal(">="))
+		Expect(f.Price.MaxOp()).To(Equal("<"))
+		Expect(f.Age).To(Equal(urlstruct.Range[uint8]{Min: 17, HasMin: true, MinExclusive: true}))
+	})
+
+	It("decodes slices of ranges", func() {
+		f := new(RangeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"scores": {"1..2", "5.."}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Scores).To(Equal([]urlstruct.Range[int]{
+			{Min: 1, Max: 2, HasMin: true, HasMax: true},
+			{Min: 5, HasMin: true},
+		}))
+	})
+
+	It("rejects invalid ranges", func() {
+		tests := []struct {
+			values url.Values
+			err    string
+		}{
+			{url.Values{"count": {"100..10"}}, `urlstruct: can't decode "count": range min 100 is greater than max 10`},
+			{url.Values{"count": {".."}}, `urlstruct: can't decode "count": invalid range ".."`},
+			{url.Values{"count": {"[1)"}}, `urlstruct: can't decode "count": invalid range "[1)"`},
+			{url.Values{"count": {"a..b"}}, `urlstruct: can't decode "count": strconv.ParseInt: parsing "a": invalid syntax`},
+			{url.Values{"age": {"..256"}}, `urlstruct: can't decode "age": value 256 is out of range for uint8`},
+			{url.Values{"price": {"NaN.."}}, `urlstruct: can't decode "price": invalid number "NaN"`},
+		}
+		for _, test := range tests {
+			err := urlstruct.Unmarshal(ctx, test.values, new(RangeFilter))
+			Expect(err).To(MatchError(test.err))
+		}
+
+		// The error is reported for the param that is decoded last.
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"price[gte]": {"10"},
+			"price[lte]": {"1"},
+		}, new(RangeFilter))
+		Expect(err).To(MatchError(HaveSuffix("range min 10 is greater than max 1")))
+	})
+
+	It("checks and formats bounds", func() {
+		r := urlstruct.Range[int]{Min: 10, Max: 100, HasMin: true, HasMax: true, MaxExclusive: true}
+		Expect(r.Contains(10)).To(BeTrue())
+		Expect(r.Contains(99)).To(BeTrue())
+		Expect(r.Contains(100)).To(BeFalse())
+		Expect(r.Contains(9)).To(BeFalse())
+		Expect(r.String()).To(Equal("[10,100)"))
+
+		Expect(urlstruct.Range[int]{Min: 10, HasMin: true}.String()).To(Equal("10.."))
+		Expect(urlstruct.Range[int]{}.IsZero()).To(BeTrue())
+		Expect(urlstruct.Range[int]{}.Contains(-1)).To(BeTrue())
+
+		var parsed urlstruct.Range[int]
+		Expect(parsed.UnmarshalText([]byte(r.String()))).To(Succeed())
+		Expect(parsed).To(Equal(r))
+	})
+})
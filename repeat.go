@@ -0,0 +1,116 @@
+package urlstruct
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+// RepeatPolicy controls how the fields that hold a single value decode
+// repeated params like limit=10&limit=5000. The policy is selected with
+// Decoder.Repeat or per field with the `repeat` tag option, e.g.
+// `urlstruct:",repeat:error"`. Slices and arrays always get all values.
+type RepeatPolicy int
+
+const (
+	// RepeatFirst uses the first value. It is the default.
+	RepeatFirst RepeatPolicy = iota
+	// RepeatLast uses the last value.
+	RepeatLast
+	// RepeatError rejects repeated params with ErrRepeated.
+	RepeatError
+	// RepeatJoin joins the values with commas.
+	RepeatJoin
+)
+
+var repeatPolicies = map[string]RepeatPolicy{
+	"first": RepeatFirst,
+	"last":  RepeatLast,
+	"error": RepeatError,
+	"join":  RepeatJoin,
+}
+
+// ErrRepeated is returned for repeated params with the RepeatError policy.
+var ErrRepeated = errors.New("param is repeated")
+
+// apply reduces the values to a single value.
+func (p RepeatPolicy) apply(values []string) ([]string, error) {
+	if len(values) <= 1 {
+		return values, nil
+	}
+	switch p {
+	case RepeatLast:
+		return values[len(values)-1:], nil
+	case RepeatError:
+		return nil, ErrRepeated
+	case RepeatJoin:
+		return []string{strings.Join(values, ",")}, nil
+	default:
+		return values[:1], nil
+	}
+}
+
+type repeatPolicyKey struct{}
+
+func withRepeatPolicy(ctx context.Context, policy RepeatPolicy) context.Context {
+	return context.WithValue(ctx, repeatPolicyKey{}, policy)
+}
+
+// RepeatPolicyFromContext returns the policy of the Decoder that calls
+// UnmarshalValues, so Unmarshaler implementations like Pager can follow it.
+func RepeatPolicyFromContext(ctx context.Context) RepeatPolicy {
+	if ctx == nil {
+		return RepeatFirst
+	}
+	policy, _ := ctx.Value(repeatPolicyKey{}).(RepeatPolicy)
+	return policy
+}
+
+func (d *Decoder) repeatPolicy(tag *tagparser.Tag) RepeatPolicy {
+	if s, ok := tagOption(tag, "repeat"); ok {
+		if policy, ok := repeatPolicies[s]; ok {
+			return policy
+		}
+	}
+	return d.Repeat
+}
+
+// repeatScanner applies the repeat policy to the scanner of a field that
+// holds a single value.
+func (d *Decoder) repeatScanner(typ reflect.Type, tag *tagparser.Tag, scan scannerFunc) scannerFunc {
+	if scan == nil || !isSingleValue(typ, tag) {
+		return scan
+	}
+
+	policy := d.repeatPolicy(tag)
+	if policy == RepeatFirst {
+		return scan
+	}
+
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		values, err := policy.apply(values)
+		if err != nil {
+			return err
+		}
+		return scan(ctx, v, values)
+	}
+}
+
+func isSingleValue(typ reflect.Type, tag *tagparser.Tag) bool {
+	if elem, ok := nullable(typ); ok {
+		typ = elem
+	}
+	if hasUnmarshaler(typ, tag) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return typ.Elem().Kind() == reflect.Uint8
+	case reflect.Map:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package urlstruct_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type RepeatFilter struct {
+	urlstruct.Pager
+
+	Query  string
+	Last   string   `urlstruct:",repeat:last"`
+	Strict int      `urlstruct:",repeat:error"`
+	Joined string   `urlstruct:",repeat:join"`
+	Tags   []string `urlstruct:",repeat:error"`
+	Data   []byte   `urlstruct:",repeat:last"`
+}
+
+type InvalidRepeatFilter struct {
+	Query string `urlstruct:",repeat:random"`
+}
+
+var _ = Describe("RepeatPolicy", func() {
+	ctx := context.TODO()
+
+	It("uses the first value by default", func() {
+		f := new(RepeatFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"query": {"a", "b"},
+			"limit": {"10", "5000"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Query).To(Equal("a"))
+		Expect(f.Limit).To(Equal(10))
+	})
+
+	It("uses the tag option", func() {
+		f := new(RepeatFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"last":   {"a", "b"},
+			"strict": {"1"},
+			"joined": {"a", "b", "c"},
+			"tags":   {"a", "b"},
+			"data":   {"a", "b"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Last).To(Equal("b"))
+		Expect(f.Strict).To(Equal(1))
+		Expect(f.Joined).To(Equal("a,b,c"))
+		Expect(f.Tags).To(Equal([]string{"a", "b"}))
+		Expect(f.Data).To(Equal([]byte("b")))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"strict": {"1", "2"}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "strict": param is repeated`))
+		Expect(errors.Is(err, urlstruct.ErrRepeated)).To(BeTrue())
+	})
+
+	It("uses the decoder option for fields and Pager", func() {
+		d := &urlstruct.Decoder{Repeat: urlstruct.RepeatError}
+
+		err := d.Unmarshal(ctx, url.Values{"query": {"a", "b"}}, new(RepeatFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "query": param is repeated`))
+
+		err = d.Unmarshal(ctx, url.Values{"limit": {"10", "5000"}}, new(RepeatFilter))
+		Expect(err).To(MatchError(urlstruct.ErrRepeated))
+
+		f := new(RepeatFilter)
+		err = d.Unmarshal(ctx, url.Values{"last": {"a", "b"}, "limit": {"10"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Last).To(Equal("b"))
+		Expect(f.Limit).To(Equal(10))
+
+		d = &urlstruct.Decoder{Repeat: urlstruct.RepeatLast}
+		f = new(RepeatFilter)
+		err = d.Unmarshal(ctx, url.Values{"limit": {"10", "50"}, "page": {"1", "3"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Limit).To(Equal(50))
+		Expect(f.GetPage()).To(Equal(3))
+	})
+
+	It("applies the policy in Values", func() {
+		values := urlstruct.Values{"q": {"a", "b"}, "one": {"x"}}
+
+		s, err := values.Value("q", urlstruct.RepeatLast)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal("b"))
+
+		_, err = values.Value("q", urlstruct.RepeatError)
+		Expect(err).To(MatchError(urlstruct.ErrRepeated))
+
+		s, err = values.Value("one", urlstruct.RepeatError)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal("x"))
+
+		s, err = values.Value("missing", urlstruct.RepeatError)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(""))
+	})
+
+	It("applies the policy in the typed Values getters", func() {
+		values := urlstruct.Values{
+			"int":      {"1", "2"},
+			"float":    {"1.5", "2.5"},
+			"bool":     {"false", "true"},
+			"time":     {"1970-01-01T00:00:00Z", "1970-01-02T00:00:00Z"},
+			"duration": {"1h", "2h"},
+		}
+
+		n, err := values.IntValue("int", urlstruct.RepeatLast)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+		Expect(values.MaybeInt("int")).To(Equal(1))
+
+		n64, err := values.Int64Value("int", urlstruct.RepeatFirst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n64).To(Equal(int64(1)))
+		n64, err = values.Int64Value("int", urlstruct.RepeatLast)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n64).To(Equal(int64(2)))
+
+		f, err := values.Float64Value("float", urlstruct.RepeatLast)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f).To(Equal(2.5))
+		Expect(values.MaybeFloat64("float")).To(Equal(1.5))
+
+		flag, err := values.BoolValue("bool", urlstruct.RepeatLast)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(flag).To(BeTrue())
+		Expect(values.MaybeBool("bool")).To(BeFalse())
+
+		tm, err := values.TimeValue("time", urlstruct.RepeatLast)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tm.Unix()).To(Equal(int64(86400)))
+		Expect(values.MaybeTime("time").Unix()).To(Equal(int64(0)))
+
+		dur, err := values.DurationValue("duration", urlstruct.RepeatLast)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dur).To(Equal(2 * time.Hour))
+		Expect(values.MaybeDuration("duration")).To(Equal(time.Hour))
+
+		for name, get := range map[string]func(string, urlstruct.RepeatPolicy) error{
+			"int": func(name string, p urlstruct.RepeatPolicy) error {
+				_, err := values.IntValue(name, p)
+				return err
+			},
+			"float": func(name string, p urlstruct.RepeatPolicy) error {
+				_, err := values.Float64Value(name, p)
+				return err
+			},
+			"bool": func(name string, p urlstruct.RepeatPolicy) error {
+				_, err := values.BoolValue(name, p)
+				return err
+			},
+			"time": func(name string, p urlstruct.RepeatPolicy) error {
+				_, err := values.TimeValue(name, p)
+				return err
+			},
+			"duration": func(name string, p urlstruct.RepeatPolicy) error {
+				_, err := values.DurationValue(name, p)
+				return err
+			},
+		} {
+			Expect(get(name, urlstruct.RepeatError)).To(MatchError(urlstruct.ErrRepeated), name)
+			Expect(get("missing", urlstruct.RepeatError)).To(Succeed(), name)
+		}
+		_, err = values.Int64Value("int", urlstruct.RepeatError)
+		Expect(err).To(MatchError(urlstruct.ErrRepeated))
+	})
+
+	It("rejects unknown policies", func() {
+		err := urlstruct.Validate(reflect.TypeOf(InvalidRepeatFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.InvalidRepeatFilter: ` +
+			`field Query has invalid repeat policy "random"`))
+	})
+})
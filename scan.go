@@ -1,342 +1,358 @@
 package urlstruct
 
 import (
-	"database/sql"
+	"context"
 	"encoding"
+	"errors"
+	"flag"
 	"fmt"
 	"reflect"
 	"strconv"
 	"time"
+
+	"github.com/vmihailenco/tagparser"
 )
 
 var (
-	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
-	timeType            = reflect.TypeOf((*time.Time)(nil)).Elem()
-	durationType        = reflect.TypeOf((*time.Duration)(nil)).Elem()
-	nullBoolType        = reflect.TypeOf((*sql.NullBool)(nil)).Elem()
-	nullInt64Type       = reflect.TypeOf((*sql.NullInt64)(nil)).Elem()
-	nullFloat64Type     = reflect.TypeOf((*sql.NullFloat64)(nil)).Elem()
-	nullStringType      = reflect.TypeOf((*sql.NullString)(nil)).Elem()
-	mapStringStringType = reflect.TypeOf((*map[string]string)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	flagValueType         = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	timeType              = reflect.TypeOf((*time.Time)(nil)).Elem()
+	timeRangeType         = reflect.TypeOf((*TimeRange)(nil)).Elem()
+	durationType          = reflect.TypeOf((*time.Duration)(nil)).Elem()
+	mapStringStringType   = reflect.TypeOf((*map[string]string)(nil)).Elem()
 )
 
-type scannerFunc func(v reflect.Value, values []string) error
+type scannerFunc func(ctx context.Context, v reflect.Value, values []string) error
 
-func scanner(typ reflect.Type) scannerFunc {
-	if typ == timeType {
-		return scanTime
+// Scan decodes the values into dst, which must be a non-nil pointer.
+// Common types are decoded without reflection, which makes Scan suitable
+// for the code generated by urlstruct-gen. Without values dst is left
+// unchanged, except that bools are set to true.
+func Scan(ctx context.Context, dst interface{}, values []string) error {
+	if len(values) == 0 {
+		return scanValue(ctx, dst, values)
 	}
 
-	if typ.Implements(textUnmarshalerType) {
-		return scanTextUnmarshaler
+	switch dst := dst.(type) {
+	case *string:
+		*dst = values[0]
+		return nil
+	case *bool:
+		f, err := defaultBoolParser(values[0])
+		if err != nil {
+			return err
+		}
+		*dst = f
+		return nil
+	case *int:
+		return scanSigned(dst, values[0])
+	case *int8:
+		return scanSigned(dst, values[0])
+	case *int16:
+		return scanSigned(dst, values[0])
+	case *int32:
+		return scanSigned(dst, values[0])
+	case *int64:
+		return scanSigned(dst, values[0])
+	case *uint:
+		return scanUnsigned(dst, values[0])
+	case *uint8:
+		return scanUnsigned(dst, values[0])
+	case *uint16:
+		return scanUnsigned(dst, values[0])
+	case *uint32:
+		return scanUnsigned(dst, values[0])
+	case *uint64:
+		return scanUnsigned(dst, values[0])
+	case *float32:
+		n, err := strconv.ParseFloat(values[0], 32)
+		if err != nil {
+			return err
+		}
+		*dst = float32(n)
+		return nil
+	case *float64:
+		n, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	case *time.Time:
+		tm, err := defaultTimeParser.parse(ctx, values[0])
+		if err != nil {
+			return err
+		}
+		*dst = tm
+		return nil
+	case *TimeRange:
+		r, err := defaultTimeParser.parseRange(ctx, values[0])
+		if err != nil {
+			return err
+		}
+		*dst = r
+		return nil
+	case encoding.TextUnmarshaler:
+		return dst.UnmarshalText([]byte(values[0]))
+	case *time.Duration:
+		dur, err := time.ParseDuration(values[0])
+		if err != nil {
+			return err
+		}
+		*dst = dur
+		return nil
+	case *[]string:
+		*dst = values
+		return nil
 	}
-	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
-		return scanTextUnmarshalerAddr
+	return scanValue(ctx, dst, values)
+}
+
+func scanValue(ctx context.Context, dst interface{}, values []string) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("urlstruct: Scan(non-pointer %T)", dst)
 	}
+	v = v.Elem()
 
-	switch typ {
-	case durationType:
-		return scanDuration
-	case nullBoolType:
-		return scanNullBool
-	case nullInt64Type:
-		return scanNullInt64
-	case nullFloat64Type:
-		return scanNullFloat64
-	case nullStringType:
-		return scanNullString
-	case mapStringStringType:
-		return scanMapStringString
+	scan := defaultDecoder.fieldScanner(v.Type(), nil)
+	if scan == nil {
+		return fmt.Errorf("urlstruct: Scan(unsupported %s)", v.Type())
 	}
+	return scan(ctx, v, values)
+}
 
-	switch typ.Kind() {
-	case reflect.Bool:
-		return scanBool
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return scanInt64
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return scanUint64
-	case reflect.Float32:
-		return scanFloat32
-	case reflect.Float64:
-		return scanFloat64
-	case reflect.String:
-		return scanString
+// fieldScanner returns the scanner for a struct field. The tag is optional.
+func (d *Decoder) fieldScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	scan := d._fieldScanner(typ, tag)
+	if scan == nil {
+		return nil
 	}
-	return nil
+	return noValuesScanner(typ, tag, scan)
 }
 
-func sliceScanner(typ reflect.Type) scannerFunc {
-	switch typ.Elem().Kind() {
-	case reflect.Int:
-		return scanIntSlice
-	case reflect.Int32:
-		return scanInt32Slice
-	case reflect.Int64:
-		return scanInt64Slice
-	case reflect.String:
-		return scanStringSlice
+func (d *Decoder) _fieldScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	if typ.Kind() == reflect.Ptr {
+		return d.ptrScanner(typ, tag)
+	}
+	if hasUnmarshaler(typ, tag) {
+		return d.scanner(typ, tag)
+	}
+	if typ.Kind() == reflect.Struct && typ.Implements(optionalType) {
+		return d.optionalScanner(typ, tag)
 	}
 
-	if elementScanner := scanner(typ.Elem()); elementScanner != nil {
-		return func(v reflect.Value, values []string) error {
-			nn := reflect.MakeSlice(typ, 0, len(values))
-			for _, s := range values {
-				n := reflect.New(typ.Elem())
-				err := elementScanner(n.Elem(), []string{s})
-				if err != nil {
-					return err
-				}
-				nn = reflect.Append(nn, n.Elem())
-			}
-			v.Set(nn)
-
-			return nil
+	switch typ.Kind() {
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return bytesScanner(tag)
 		}
+		return d.sliceScanner(typ, tag)
+	case reflect.Array:
+		return d.arrayScanner(typ, tag)
 	}
-
-	return nil
+	return d.scanner(typ, tag)
 }
 
-func scanTextUnmarshaler(v reflect.Value, values []string) error {
-	if v.IsNil() {
-		v.Set(reflect.New(v.Type().Elem()))
+// noValuesScanner handles params without values, e.g. url.Values{"name": {}},
+// which url.ParseQuery never returns. They are ignored like absent params,
+// except that bools are decoded as flags, the same as params with an empty
+// value. The other scanners can assume at least one value.
+func noValuesScanner(typ reflect.Type, tag *tagparser.Tag, scan scannerFunc) scannerFunc {
+	flag := isFlag(typ, tag)
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		if len(values) > 0 {
+			return scan(ctx, v, values)
+		}
+		if flag {
+			return scan(ctx, v, []string{""})
+		}
+		return nil
 	}
+}
 
-	u := v.Interface().(encoding.TextUnmarshaler)
-	return u.UnmarshalText([]byte(values[0]))
+func hasOption(tag *tagparser.Tag, name string) bool {
+	return tag != nil && tag.HasOption(name)
 }
 
-func scanTextUnmarshalerAddr(v reflect.Value, values []string) error {
-	if !v.CanAddr() {
-		return fmt.Errorf("pg: Scan(nonsettable %s)", v.Type())
+func (d *Decoder) scanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	switch typ {
+	case timeType:
+		return d.timeParser(tag).scan
+	case timeRangeType:
+		return d.timeParser(tag).scanRange
+	}
+
+	if scan := unmarshalerScanner(typ, tag); scan != nil {
+		return scan
+	}
+	if isNullStruct(typ) {
+		return d.nullStructScanner(typ, tag)
 	}
-	u := v.Addr().Interface().(encoding.TextUnmarshaler)
-	return u.UnmarshalText([]byte(values[0]))
-}
 
-func scanBool(v reflect.Value, values []string) error {
-	f, err := strconv.ParseBool(values[0])
-	if err != nil {
-		return err
+	switch typ {
+	case durationType:
+		return d.durationScanner(tag)
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return boolScanner(d.boolParser())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intScanner(typ, hasOption(tag, "clamp"))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uintScanner(typ, hasOption(tag, "clamp"))
+	case reflect.Float32:
+		return scanFloat32
+	case reflect.Float64:
+		return scanFloat64
+	case reflect.String:
+		if e, _ := enumFor(typ, tag); e != nil {
+			return e.scan
+		}
+		return scanString
+	case reflect.Map:
+		return d.mapScanner(typ, tag)
 	}
-	v.SetBool(f)
 	return nil
 }
 
-func scanInt64(v reflect.Value, values []string) error {
-	n, err := strconv.ParseInt(values[0], 10, 64)
-	if err != nil {
-		return err
+// ptr returns a pointer to the value if it is addressable. Assigning through
+// the pointer avoids boxing the value in reflect.ValueOf.
+func ptr(v reflect.Value) interface{} {
+	if v.CanAddr() && v.CanInterface() {
+		return v.Addr().Interface()
 	}
-	v.SetInt(n)
 	return nil
 }
 
-func scanUint64(v reflect.Value, values []string) error {
-	n, err := strconv.ParseUint(values[0], 10, 64)
-	if err != nil {
-		return err
+// intScanner returns a scanner for the signed integer type. Values that
+// don't fit into the type are rejected with a RangeError or, with the
+// `clamp` tag option, saturated to the minimum or maximum of the type.
+func intScanner(typ reflect.Type, clamp bool) scannerFunc {
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		n, err := parseInt(values[0], typ, clamp)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+		return nil
 	}
-	v.SetUint(n)
-	return nil
 }
 
-func scanFloat32(v reflect.Value, values []string) error {
-	return scanFloat(v, values, 32)
+func uintScanner(typ reflect.Type, clamp bool) scannerFunc {
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		n, err := parseUint(values[0], typ, clamp)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+		return nil
+	}
 }
 
-func scanFloat64(v reflect.Value, values []string) error {
-	return scanFloat(v, values, 64)
+// A RangeError is returned for values that are out of range for the type.
+type RangeError struct {
+	Value string
+	Type  reflect.Type
 }
 
-func scanFloat(v reflect.Value, values []string, bits int) error {
-	n, err := strconv.ParseFloat(values[0], bits)
-	if err != nil {
-		return err
-	}
-	v.SetFloat(n)
-	return nil
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("value %s is out of range for %s", e.Value, e.Type)
 }
 
-func scanString(v reflect.Value, values []string) error {
-	v.SetString(values[0])
-	return nil
+func (e *RangeError) Unwrap() error {
+	return strconv.ErrRange
 }
 
-func scanTime(v reflect.Value, values []string) error {
-	tm, err := parseTime(values[0])
-	if err != nil {
-		return err
+func parseInt(s string, typ reflect.Type, clamp bool) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, typ.Bits())
+	if err != nil && errors.Is(err, strconv.ErrRange) {
+		// ParseInt returns the closest value that fits into the type.
+		if clamp {
+			return n, nil
+		}
+		return 0, &RangeError{Value: s, Type: typ}
 	}
-	v.Set(reflect.ValueOf(tm))
-	return nil
+	return n, err
 }
 
-func parseTime(s string) (time.Time, error) {
-	n, err := strconv.ParseInt(s, 10, 64)
+func parseUint(s string, typ reflect.Type, clamp bool) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, typ.Bits())
 	if err == nil {
-		return time.Unix(n, 0), nil
+		return n, nil
 	}
 
-	if len(s) >= 5 && s[4] == '-' {
-		return time.Parse(time.RFC3339Nano, s)
+	if errors.Is(err, strconv.ErrRange) {
+		if clamp {
+			return n, nil
+		}
+		return 0, &RangeError{Value: s, Type: typ}
 	}
 
-	if len(s) == 15 {
-		const basicFormat = "20060102T150405"
-		return time.Parse(basicFormat, s)
+	if len(s) > 1 && s[0] == '-' && isInteger(s) {
+		if clamp {
+			return 0, nil
+		}
+		return 0, &RangeError{Value: s, Type: typ}
 	}
-
-	const basicFormat = "20060102T150405-07:00"
-	return time.Parse(basicFormat, s)
+	return 0, err
 }
 
-func scanDuration(v reflect.Value, values []string) error {
-	dur, err := time.ParseDuration(values[0])
+func scanSigned[T signed](dst *T, s string) error {
+	n, err := parseInt(s, reflect.TypeOf(dst).Elem(), false)
 	if err != nil {
 		return err
 	}
-	v.SetInt(int64(dur))
+	*dst = T(n)
 	return nil
 }
 
-func scanNullBool(v reflect.Value, values []string) error {
-	value := sql.NullBool{
-		Valid: true,
-	}
-
-	s := values[0]
-	if s == "" {
-		v.Set(reflect.ValueOf(value))
-		return nil
-	}
-
-	f, err := strconv.ParseBool(s)
+func scanUnsigned[T unsigned](dst *T, s string) error {
+	n, err := parseUint(s, reflect.TypeOf(dst).Elem(), false)
 	if err != nil {
 		return err
 	}
-
-	value.Bool = f
-	v.Set(reflect.ValueOf(value))
-
+	*dst = T(n)
 	return nil
 }
 
-func scanNullInt64(v reflect.Value, values []string) error {
-	value := sql.NullInt64{
-		Valid: true,
-	}
-
-	s := values[0]
-	if s == "" {
-		v.Set(reflect.ValueOf(value))
-		return nil
-	}
-
-	n, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		return err
-	}
-
-	value.Int64 = n
-	v.Set(reflect.ValueOf(value))
-
-	return nil
+func scanFloat32(ctx context.Context, v reflect.Value, values []string) error {
+	return scanFloat(v, values, 32)
 }
 
-func scanNullFloat64(v reflect.Value, values []string) error {
-	value := sql.NullFloat64{
-		Valid: true,
-	}
-
-	s := values[0]
-	if s == "" {
-		v.Set(reflect.ValueOf(value))
-		return nil
-	}
+func scanFloat64(ctx context.Context, v reflect.Value, values []string) error {
+	return scanFloat(v, values, 64)
+}
 
-	n, err := strconv.ParseFloat(s, 64)
+func scanFloat(v reflect.Value, values []string, bits int) error {
+	n, err := strconv.ParseFloat(values[0], bits)
 	if err != nil {
 		return err
 	}
-
-	value.Float64 = n
-	v.Set(reflect.ValueOf(value))
-
-	return nil
-}
-
-func scanNullString(v reflect.Value, values []string) error {
-	value := sql.NullString{
-		Valid: true,
-	}
-
-	s := values[0]
-	if s == "" {
-		v.Set(reflect.ValueOf(value))
-		return nil
-	}
-
-	value.String = s
-	v.Set(reflect.ValueOf(value))
-
+	v.SetFloat(n)
 	return nil
 }
 
-func scanMapStringString(v reflect.Value, values []string) error {
-	if len(values)%2 != 0 {
-		return nil
-	}
-
-	m := make(map[string]string)
-	for i := 0; i < len(values); i += 2 {
-		m[values[i]] = values[i+1]
-	}
-	v.Set(reflect.ValueOf(m))
+func scanString(ctx context.Context, v reflect.Value, values []string) error {
+	v.SetString(values[0])
 	return nil
 }
 
-func scanIntSlice(v reflect.Value, values []string) error {
-	nn := make([]int, 0, len(values))
-	for _, s := range values {
-		n, err := strconv.Atoi(s)
-		if err != nil {
-			return err
-		}
-		nn = append(nn, n)
+// isInteger is a cheap check that avoids allocating strconv errors
+// for the values that are obviously not integers.
+func isInteger(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
 	}
-	v.Set(reflect.ValueOf(nn))
-	return nil
-}
-
-func scanInt32Slice(v reflect.Value, values []string) error {
-	nn := make([]int32, 0, len(values))
-	for _, s := range values {
-		n, err := strconv.ParseInt(s, 10, 32)
-		if err != nil {
-			return err
-		}
-		nn = append(nn, int32(n))
+	if s == "" {
+		return false
 	}
-	v.Set(reflect.ValueOf(nn))
-	return nil
-}
-
-func scanInt64Slice(v reflect.Value, values []string) error {
-	nn := make([]int64, 0, len(values))
-	for _, s := range values {
-		n, err := strconv.ParseInt(s, 10, 64)
-		if err != nil {
-			return err
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
 		}
-		nn = append(nn, n)
 	}
-	v.Set(reflect.ValueOf(nn))
-	return nil
-}
-
-func scanStringSlice(v reflect.Value, values []string) error {
-	v.Set(reflect.ValueOf(values))
-	return nil
+	return true
 }
@@ -0,0 +1,66 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+// mapScanner decodes map[K]V from the `name[key]` params, which are passed
+// as key and value pairs. Keys and values are decoded like single values
+// of their types, so they can be ints, enums or the types that decode
+// themselves. The tag options apply to the values.
+func (d *Decoder) mapScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	if typ == mapStringStringType {
+		return scanMapStringString
+	}
+	if typ.Elem().Kind() == reflect.Map {
+		return nil
+	}
+
+	keyScanner := d.scanner(typ.Key(), nil)
+	valueScanner := d.scanner(typ.Elem(), tag)
+	if keyScanner == nil || valueScanner == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		if len(values)%2 != 0 {
+			return errOddMapValues(values)
+		}
+
+		m := reflect.MakeMapWithSize(typ, len(values)/2)
+		for i := 0; i < len(values); i += 2 {
+			key := reflect.New(typ.Key()).Elem()
+			if err := keyScanner(ctx, key, values[i:i+1]); err != nil {
+				return fmt.Errorf("invalid key %q: %w", values[i], err)
+			}
+			value := reflect.New(typ.Elem()).Elem()
+			if err := valueScanner(ctx, value, values[i+1:i+2]); err != nil {
+				return fmt.Errorf("key %q: %w", values[i], err)
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+		return nil
+	}
+}
+
+func scanMapStringString(ctx context.Context, v reflect.Value, values []string) error {
+	if len(values)%2 != 0 {
+		return errOddMapValues(values)
+	}
+
+	m := make(map[string]string)
+	for i := 0; i < len(values); i += 2 {
+		m[values[i]] = values[i+1]
+	}
+	v.Set(reflect.ValueOf(m))
+	return nil
+}
+
+func errOddMapValues(values []string) error {
+	return fmt.Errorf("got %d values, wanted key and value pairs", len(values))
+}
@@ -0,0 +1,221 @@
+package urlstruct
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+type signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+type unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+type float interface {
+	~float32 | ~float64
+}
+
+func (d *Decoder) sliceScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	elem := typ.Elem()
+	clamp := hasOption(tag, "clamp")
+
+	if e, _ := enumFor(elem, tag); e != nil {
+		return e.scanSlice
+	}
+
+	// Named element types, e.g. `type Status string`, are handled by
+	// the element scanner below, because []Status is not a []string.
+	if elem.PkgPath() == "" {
+		switch elem.Kind() {
+		case reflect.Bool:
+			return scanSlice(ignoreContext(d.boolParser()))
+		case reflect.Int:
+			return scanSlice(signedParser[int](clamp))
+		case reflect.Int8:
+			return scanSlice(signedParser[int8](clamp))
+		case reflect.Int16:
+			return scanSlice(signedParser[int16](clamp))
+		case reflect.Int32:
+			return scanSlice(signedParser[int32](clamp))
+		case reflect.Int64:
+			return scanSlice(signedParser[int64](clamp))
+		case reflect.Uint:
+			return scanSlice(unsignedParser[uint](clamp))
+		case reflect.Uint16:
+			return scanSlice(unsignedParser[uint16](clamp))
+		case reflect.Uint32:
+			return scanSlice(unsignedParser[uint32](clamp))
+		case reflect.Uint64:
+			return scanSlice(unsignedParser[uint64](clamp))
+		case reflect.Float32:
+			return scanSlice(floatParser[float32]())
+		case reflect.Float64:
+			return scanSlice(floatParser[float64]())
+		case reflect.String:
+			return scanStringSlice
+		}
+	}
+
+	switch elem {
+	case timeType:
+		return scanSlice(d.timeParser(tag).parse)
+	case durationType:
+		return scanSlice(ignoreContext(d.durationFormat(tag).Parse))
+	}
+
+	if elementScanner := d.scanner(elem, tag); elementScanner != nil {
+		return func(ctx context.Context, v reflect.Value, values []string) error {
+			nn := reflect.MakeSlice(typ, len(values), len(values))
+			for i, s := range values {
+				if err := elementScanner(ctx, nn.Index(i), []string{s}); err != nil {
+					return err
+				}
+			}
+			v.Set(nn)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// scanSlice returns a scanner that parses each value into a []T.
+func scanSlice[T any](parse func(context.Context, string) (T, error)) scannerFunc {
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		nn := make([]T, len(values))
+		for i, s := range values {
+			n, err := parse(ctx, s)
+			if err != nil {
+				return err
+			}
+			nn[i] = n
+		}
+
+		if p, ok := ptr(v).(*[]T); ok {
+			*p = nn
+			return nil
+		}
+		v.Set(reflect.ValueOf(nn))
+		return nil
+	}
+}
+
+func ignoreContext[T any](parse func(string) (T, error)) func(context.Context, string) (T, error) {
+	return func(_ context.Context, s string) (T, error) {
+		return parse(s)
+	}
+}
+
+func signedParser[T signed](clamp bool) func(context.Context, string) (T, error) {
+	typ := reflect.TypeOf(T(0))
+	return func(_ context.Context, s string) (T, error) {
+		n, err := parseInt(s, typ, clamp)
+		return T(n), err
+	}
+}
+
+func unsignedParser[T unsigned](clamp bool) func(context.Context, string) (T, error) {
+	typ := reflect.TypeOf(T(0))
+	return func(_ context.Context, s string) (T, error) {
+		n, err := parseUint(s, typ, clamp)
+		return T(n), err
+	}
+}
+
+func floatParser[T float]() func(context.Context, string) (T, error) {
+	bits := reflect.TypeOf(T(0)).Bits()
+	return func(_ context.Context, s string) (T, error) {
+		n, err := strconv.ParseFloat(s, bits)
+		return T(n), err
+	}
+}
+
+func scanStringSlice(ctx context.Context, v reflect.Value, values []string) error {
+	if p, ok := ptr(v).(*[]string); ok {
+		*p = values
+		return nil
+	}
+	v.Set(reflect.ValueOf(values))
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// bytesScanner decodes []byte from a single value. By default the value is
+// used as is; the `base64` tag option decodes standard or URL-safe base64
+// with optional padding.
+func bytesScanner(tag *tagparser.Tag) scannerFunc {
+	if hasOption(tag, "base64") {
+		return scanBase64
+	}
+	return scanBytes
+}
+
+func scanBytes(ctx context.Context, v reflect.Value, values []string) error {
+	v.SetBytes([]byte(values[0]))
+	return nil
+}
+
+func scanBase64(ctx context.Context, v reflect.Value, values []string) error {
+	b, err := decodeBase64(values[0])
+	if err != nil {
+		return err
+	}
+	v.SetBytes(b)
+	return nil
+}
+
+var base64Replacer = strings.NewReplacer("-", "+", "_", "/")
+
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = base64Replacer.Replace(s)
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+//------------------------------------------------------------------------------
+
+// arrayScanner decodes [N]T from exactly N values. Byte arrays follow the
+// []byte policy and must decode to exactly N bytes.
+func (d *Decoder) arrayScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	if typ.Elem().Kind() == reflect.Uint8 {
+		scanBytes := bytesScanner(tag)
+		sliceType := reflect.SliceOf(typ.Elem())
+		return func(ctx context.Context, v reflect.Value, values []string) error {
+			b := reflect.New(sliceType).Elem()
+			if err := scanBytes(ctx, b, values); err != nil {
+				return err
+			}
+			if b.Len() != typ.Len() {
+				return fmt.Errorf("got %d bytes, wanted %d", b.Len(), typ.Len())
+			}
+			reflect.Copy(v, b)
+			return nil
+		}
+	}
+
+	elementScanner := d.scanner(typ.Elem(), tag)
+	if elementScanner == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		if len(values) != typ.Len() {
+			return fmt.Errorf("got %d values, wanted %d", len(values), typ.Len())
+		}
+		for i, s := range values {
+			if err := elementScanner(ctx, v.Index(i), []string{s}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,321 @@
+package urlstruct_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type Status string
+
+type SliceFilter struct {
+	Bools     []bool
+	Ints      []int
+	SmallInts []int8
+	Shorts    []int16
+	Uints     []uint
+	Ports     []uint16
+	Bigs      []uint64
+	Ratios    []float32
+	Prices    []float64
+	Statuses  []Status
+	Times     []time.Time
+	Durations []time.Duration
+	NullInts  []sql.NullInt64
+	NullStrs  []sql.NullString
+
+	Bytes  []byte
+	Base64 []byte `urlstruct:",base64"`
+
+	Clamped []int8 `urlstruct:",clamp"`
+
+	Point [2]float64
+	Hash  [4]byte
+}
+
+var _ = Describe("Slice scanners", func() {
+	ctx := context.TODO()
+
+	It("decodes slices of all scalar kinds", func() {
+		f := new(SliceFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"bools":      {"true", "f"},
+			"ints":       {"1", "-2"},
+			"small_ints": {"-128", "127"},
+			"shorts":     {"300"},
+			"uints":      {"1"},
+			"ports":      {"65535"},
+			"bigs":       {"18446744073709551615"},
+			"ratios":     {"1.5"},
+			"prices":     {"2.5", "-1"},
+			"statuses":   {"active", "deleted"},
+			"times":      {"1970-01-01T00:00:00Z", "0"},
+			"durations":  {"1s", "1m"},
+			"null_ints":  {"1", ""},
+			"null_strs":  {"a"},
+			"bytes":      {"raw value"},
+			"base64":     {"aGk-Pw"},
+			"point":      {"1.5", "2"},
+			"hash":       {"abcd"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(f.Bools).To(Equal([]bool{true, false}))
+		Expect(f.Ints).To(Equal([]int{1, -2}))
+		Expect(f.SmallInts).To(Equal([]int8{-128, 127}))
+		Expect(f.Shorts).To(Equal([]int16{300}))
+		Expect(f.Uints).To(Equal([]uint{1}))
+		Expect(f.Ports).To(Equal([]uint16{65535}))
+		Expect(f.Bigs).To(Equal([]uint64{18446744073709551615}))
+		Expect(f.Ratios).To(Equal([]float32{1.5}))
+		Expect(f.Prices).To(Equal([]float64{2.5, -1}))
+		Expect(f.Statuses).To(Equal([]Status{"active", "deleted"}))
+		Expect(f.Times).To(Equal([]time.Time{time.Unix(0, 0).UTC(), time.Unix(0, 0)}))
+		Expect(f.Durations).To(Equal([]time.Duration{time.Second, time.Minute}))
+		Expect(f.NullInts).To(Equal([]sql.NullInt64{{Int64: 1, Valid: true}, {}}))
+		Expect(f.NullStrs).To(Equal([]sql.NullString{{String: "a", Valid: true}}))
+		Expect(f.Bytes).To(Equal([]byte("raw value")))
+		Expect(f.Base64).To(Equal([]byte("hi>?")))
+		Expect(f.Point).To(Equal([2]float64{1.5, 2}))
+		Expect(f.Hash).To(Equal([4]byte{'a', 'b', 'c', 'd'}))
+	})
+
+	It("accepts padded standard base64", func() {
+		f := new(SliceFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"base64": {"aGk+Pw=="}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Base64).To(Equal([]byte("hi>?")))
+	})
+
+	It("returns range errors for narrow element types", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"small_ints": {"128"}}, new(SliceFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "small_ints": ` +
+			`value 128 is out of range for int8`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"ports": {"-1"}}, new(SliceFilter))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("checks array length", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"point": {"1", "2", "3"}}, new(SliceFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "point": got 3 values, wanted 2`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"hash": {"abc"}}, new(SliceFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "hash": got 3 bytes, wanted 4`))
+	})
+})
+
+type IntFilter struct {
+	Int8   int8
+	Int16  int16
+	Int32  int32
+	Uint8  uint8
+	Uint16 uint16
+
+	ClampInt8  int8   `urlstruct:",clamp"`
+	ClampUint8 uint8  `urlstruct:",clamp"`
+	ClampInt64 int64  `urlstruct:",clamp"`
+	ClampUint  uint32 `urlstruct:",clamp"`
+}
+
+var _ = Describe("Integer scanners", func() {
+	ctx := context.TODO()
+
+	It("returns RangeError for values that overflow the type", func() {
+		tests := []struct {
+			name  string
+			value string
+			err   string
+		}{
+			{"int8", "300", `urlstruct: can't decode "int8": value 300 is out of range for int8`},
+			{"int8", "-129", `urlstruct: can't decode "int8": value -129 is out of range for int8`},
+			{"int16", "32768", `urlstruct: can't decode "int16": value 32768 is out of range for int16`},
+			{"int32", "2147483648", `urlstruct: can't decode "int32": value 2147483648 is out of range for int32`},
+			{"uint8", "256", `urlstruct: can't decode "uint8": value 256 is out of range for uint8`},
+			{"uint16", "-1", `urlstruct: can't decode "uint16": value -1 is out of range for uint16`},
+		}
+		for _, test := range tests {
+			err := urlstruct.Unmarshal(ctx, url.Values{test.name: {test.value}}, new(IntFilter))
+			Expect(err).To(MatchError(test.err))
+
+			var rangeErr *urlstruct.RangeError
+			Expect(errors.As(err, &rangeErr)).To(BeTrue())
+			Expect(errors.Is(err, strconv.ErrRange)).To(BeTrue())
+		}
+	})
+
+	It("decodes values in range", func() {
+		f := new(IntFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"int8":   {"-128"},
+			"uint8":  {"255"},
+			"uint16": {"65535"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Int8).To(Equal(int8(-128)))
+		Expect(f.Uint8).To(Equal(uint8(255)))
+		Expect(f.Uint16).To(Equal(uint16(65535)))
+	})
+
+	It("clamps values with the clamp option", func() {
+		f := new(IntFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"clamp_int8":  {"300"},
+			"clamp_uint8": {"-5"},
+			"clamp_int64": {"-99999999999999999999"},
+			"clamp_uint":  {"99999999999"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.ClampInt8).To(Equal(int8(127)))
+		Expect(f.ClampUint8).To(Equal(uint8(0)))
+		Expect(f.ClampInt64).To(Equal(int64(math.MinInt64)))
+		Expect(f.ClampUint).To(Equal(uint32(math.MaxUint32)))
+
+		sf := new(SliceFilter)
+		err = urlstruct.Unmarshal(ctx, url.Values{"clamped": {"-200", "5", "200"}}, sf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sf.Clamped).To(Equal([]int8{-128, 5, 127}))
+	})
+
+	It("still rejects invalid syntax with the clamp option", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"clamp_int8": {"x"}}, new(IntFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "clamp_int8": ` +
+			`strconv.ParseInt: parsing "x": invalid syntax`))
+	})
+})
+
+type NoValuesFilter struct {
+	Bool     bool
+	Flag     Flag
+	Int      int
+	Uint     uint8
+	Float    float64
+	String   string
+	Color    Color
+	Time     time.Time
+	Period   urlstruct.TimeRange
+	Day      urlstruct.Date
+	Duration time.Duration
+	Count    urlstruct.Range[int]
+
+	NullBool   sql.NullBool
+	NullString sql.NullString
+
+	Ints  []int
+	Bools []bool
+	Point [2]float64
+	Bytes []byte
+	Map   map[string]string
+	Sub   struct {
+		Count int
+	}
+
+	Custom CustomField
+}
+
+type Flag bool
+
+func newNoValuesFilter() *NoValuesFilter {
+	f := &NoValuesFilter{
+		Int:      1,
+		Uint:     2,
+		Float:    3,
+		String:   "s",
+		Color:    "red",
+		Time:     time.Unix(1, 0),
+		Period:   urlstruct.TimeRange{Start: time.Unix(1, 0)},
+		Day:      urlstruct.Date{Year: 2024, Month: 1, Day: 1},
+		Duration: time.Second,
+		Count:    urlstruct.Range[int]{Min: 1, HasMin: true},
+
+		NullBool:   sql.NullBool{Bool: true, Valid: true},
+		NullString: sql.NullString{String: "s", Valid: true},
+
+		Ints:  []int{1},
+		Bools: []bool{false},
+		Point: [2]float64{1, 2},
+		Bytes: []byte("b"),
+		Map:   map[string]string{"a": "b"},
+
+		Custom: CustomField{S: "s"},
+	}
+	f.Sub.Count = 1
+	return f
+}
+
+var _ = Describe("Params without values", func() {
+	ctx := context.TODO()
+
+	It("are ignored except for bools", func() {
+		values := url.Values{}
+		for _, name := range []string{
+			"bool", "flag", "int", "uint", "float", "string", "color",
+			"time", "period", "day", "duration", "count", "count[gte]",
+			"null_bool", "null_string",
+			"ints", "bools", "point", "bytes", "map[a]", "map[c]", "sub[count]",
+			"custom",
+		} {
+			values[name] = []string{}
+		}
+
+		f := newNoValuesFilter()
+		err := urlstruct.Unmarshal(ctx, values, f)
+		Expect(err).NotTo(HaveOccurred())
+
+		wanted := newNoValuesFilter()
+		wanted.Bool = true
+		wanted.Flag = true
+		Expect(f).To(Equal(wanted))
+	})
+
+	It("are ignored by Scan except for bools", func() {
+		var flag bool
+		Expect(urlstruct.Scan(ctx, &flag, nil)).To(Succeed())
+		Expect(flag).To(BeTrue())
+
+		var named Flag
+		Expect(urlstruct.Scan(ctx, &named, []string{})).To(Succeed())
+		Expect(named).To(Equal(Flag(true)))
+
+		n := 1
+		Expect(urlstruct.Scan(ctx, &n, nil)).To(Succeed())
+		Expect(n).To(Equal(1))
+
+		s := "s"
+		Expect(urlstruct.Scan(ctx, &s, nil)).To(Succeed())
+		Expect(s).To(Equal("s"))
+
+		tm := time.Unix(1, 0)
+		Expect(urlstruct.Scan(ctx, &tm, nil)).To(Succeed())
+		Expect(tm).To(Equal(time.Unix(1, 0)))
+
+		ss := []string{"s"}
+		Expect(urlstruct.Scan(ctx, &ss, nil)).To(Succeed())
+		Expect(ss).To(Equal([]string{"s"}))
+
+		custom := CustomField{S: "s"}
+		Expect(urlstruct.Scan(ctx, &custom, nil)).To(Succeed())
+		Expect(custom.S).To(Equal("s"))
+
+		Expect(urlstruct.Scan(ctx, n, nil)).To(MatchError("urlstruct: Scan(non-pointer int)"))
+	})
+
+	It("match Values", func() {
+		values := urlstruct.Values{"name": {}}
+		Expect(values.MaybeBool("name")).To(BeTrue())
+		Expect(values.String("name")).To(Equal(""))
+		Expect(values.Strings("name")).To(BeEmpty())
+		Expect(values.Int("name")).To(Equal(0))
+		Expect(values.MaybeTime("name")).To(BeZero())
+		Expect(values.MaybeDuration("name")).To(BeZero())
+	})
+})
@@ -0,0 +1,94 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// Schema decodes URL query values into structs of type T. It describes T
+// once, so creating a Schema at init time reports a non-struct T or invalid
+// tags before any values are decoded.
+type Schema[T any] struct {
+	sinfo *StructInfo
+}
+
+// NewSchema returns a Schema for the struct type T. It returns an error
+// for the first field of T that is skipped because of an invalid tag, which
+// Unmarshal ignores unless the Decoder is Strict.
+func NewSchema[T any]() (*Schema[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("urlstruct: got %s, wanted %s", typ, reflect.Struct)
+	}
+
+	sinfo, err := defaultDecoder.DescribeStruct(typ)
+	if err != nil {
+		return nil, err
+	}
+	if sinfo.tagErr != nil {
+		return nil, sinfo.tagErr
+	}
+	return &Schema[T]{
+		sinfo: sinfo,
+	}, nil
+}
+
+// MustSchema is like NewSchema, but panics on error.
+func MustSchema[T any]() *Schema[T] {
+	s, err := NewSchema[T]()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// StructInfo returns the description of T.
+func (s *Schema[T]) StructInfo() *StructInfo {
+	return s.sinfo
+}
+
+// Decode decodes the URL query values into a new T.
+func (s *Schema[T]) Decode(ctx context.Context, values url.Values) (T, error) {
+	var strct T
+	err := s.Unmarshal(ctx, values, &strct)
+	return strct, err
+}
+
+// Unmarshal decodes the URL query values into the existing T.
+func (s *Schema[T]) Unmarshal(ctx context.Context, values url.Values, strct *T) error {
+	if strct == nil {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(strct)}
+	}
+	if u, ok := interface{}(strct).(GeneratedUnmarshaler); ok {
+		if err := s.sinfo.limits.check(values); err != nil {
+			return err
+		}
+		return u.UnmarshalValues(withLimits(ctx, s.sinfo.limits), values)
+	}
+	d := structDecoder{
+		v:     reflect.ValueOf(strct).Elem(),
+		sinfo: s.sinfo,
+	}
+	return d.Decode(ctx, values)
+}
+
+// Decode decodes the URL query values into a new struct of type T.
+func Decode[T any](ctx context.Context, values url.Values) (T, error) {
+	s, err := NewSchema[T]()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return s.Decode(ctx, values)
+}
+
+// MustDecode is like Decode, but panics on error.
+func MustDecode[T any](ctx context.Context, values url.Values) T {
+	strct, err := Decode[T](ctx, values)
+	if err != nil {
+		panic(err)
+	}
+	return strct
+}
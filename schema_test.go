@@ -0,0 +1,76 @@
+package urlstruct_test
+
+import (
+	"context"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+var _ = Describe("Schema", func() {
+	ctx := context.TODO()
+
+	It("decodes values into T", func() {
+		f, err := urlstruct.Decode[Filter](ctx, url.Values{
+			"field":     {"one"},
+			"multi_neq": {"3", "4"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Field).To(Equal("one"))
+		Expect(f.MultiNEQ).To(Equal([]int{3, 4}))
+		Expect(f.Count).To(Equal(1))
+	})
+
+	It("reuses the schema", func() {
+		schema := urlstruct.MustSchema[StructMap]()
+
+		for _, s := range []string{"one", "two"} {
+			sm, err := schema.Decode(ctx, url.Values{"foo": {s}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sm.Foo).To(Equal(s))
+		}
+	})
+
+	It("rejects non-struct types", func() {
+		_, err := urlstruct.NewSchema[int]()
+		Expect(err).To(MatchError("urlstruct: got int, wanted struct"))
+
+		_, err = urlstruct.NewSchema[*Filter]()
+		Expect(err).To(MatchError("urlstruct: got *urlstruct_test.Filter, wanted struct"))
+
+		_, err = urlstruct.Decode[[]string](ctx, url.Values{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(func() {
+			urlstruct.MustDecode[string](ctx, url.Values{})
+		}).To(Panic())
+	})
+
+	It("rejects invalid tags", func() {
+		_, err := urlstruct.NewSchema[struct {
+			T int `urlstruct:",repeat:bogus"`
+		}]()
+		Expect(err).To(MatchError(`urlstruct: field T has invalid repeat policy "bogus"`))
+
+		_, err = urlstruct.NewSchema[struct {
+			Sub struct {
+				Name string `urlstruct:"bad[name]"`
+			}
+		}]()
+		Expect(err).To(MatchError(`urlstruct: field Name has invalid name "bad[name]"`))
+	})
+
+	It("rejects nil pointers", func() {
+		schema := urlstruct.MustSchema[StructMap]()
+		err := schema.Unmarshal(ctx, url.Values{}, nil)
+		Expect(err).To(MatchError("urlstruct: Unmarshal(nil *urlstruct_test.StructMap)"))
+	})
+
+	It("returns decode errors", func() {
+		_, err := urlstruct.Decode[Filter](ctx, url.Values{"field_lt": {"x"}})
+		Expect(err).To(MatchError(`urlstruct: can't decode "field_lt": strconv.ParseInt: parsing "x": invalid syntax`))
+	})
+})
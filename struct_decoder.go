@@ -6,59 +6,101 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 )
 
+// mapsPool holds the scratch maps used to collect `name[key]` params.
+var mapsPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[string][]string)
+	},
+}
+
 type structDecoder struct {
 	v     reflect.Value
 	sinfo *StructInfo
-
-	decMap           map[string]*structDecoder
-	paramUnmarshaler ParamUnmarshaler
 }
 
-func newStructDecoder(v reflect.Value) *structDecoder {
-	v = reflect.Indirect(v)
-	return &structDecoder{
-		v:     v,
-		sinfo: DescribeStruct(v.Type()),
+func (d structDecoder) Decode(ctx context.Context, values url.Values) error {
+	var maps map[string][]string
+	defer func() {
+		if maps != nil {
+			for name := range maps {
+				delete(maps, name)
+			}
+			mapsPool.Put(maps)
+		}
+	}()
+
+	limits := &d.sinfo.limits
+	if err := limits.checkParams(values); err != nil {
+		return err
 	}
-}
 
-func (d *structDecoder) Decode(ctx context.Context, values url.Values) error {
-	var maps map[string][]string
+	if len(d.sinfo.beforeHooks) > 0 {
+		var err error
+		values, err = d.beforeDecode(ctx, values)
+		if err != nil {
+			return err
+		}
+		// The hooks can add params.
+		if err := limits.checkParams(values); err != nil {
+			return err
+		}
+	}
 
-	for name, values := range values {
-		name = strings.TrimPrefix(name, ":")
-		name = strings.TrimSuffix(name, "[]")
+	var winners map[*Field]fieldName
+	if d.sinfo.aliases != nil || d.sinfo.ignoreCase {
+		winners = d.sinfo.winningNames(values)
+	}
+	for name, vs := range values {
+		if err := limits.checkParam(name, vs); err != nil {
+			return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+		}
+		name = trimParam(name)
 
 		if name, key, ok := mapKey(name); ok {
-			if mdec := d.mapDecoder(name); mdec != nil {
-				if err := mdec.decodeParam(ctx, key, values); err != nil {
+			if nested, ok := d.sinfo.structs[name]; ok {
+				mdec := structDecoder{
+					v:     fieldByIndex(d.v, nested.index),
+					sinfo: nested.sinfo,
+				}
+				if err := mdec.decodeParam(ctx, key, vs, false); err != nil {
 					return err
 				}
 				continue
 			}
 
+			if len(vs) == 0 {
+				continue
+			}
 			if maps == nil {
-				maps = make(map[string][]string)
+				maps = mapsPool.Get().(map[string][]string)
 			}
-			maps[name] = append(maps[name], key, values[0])
+			if len(maps[name])/2 >= limits.MaxMapEntries && d.isMap(name) {
+				err := &LimitError{Limit: "MaxMapEntries", Max: limits.MaxMapEntries}
+				return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+			}
+			maps[name] = append(maps[name], key, vs[0])
 			continue
 		}
 
-		if err := d.decodeParam(ctx, name, values); err != nil {
+		if winners != nil && d.shadowed(winners, name) {
+			continue
+		}
+		if err := d.decodeParam(ctx, name, vs, false); err != nil {
 			return err
 		}
 	}
 
 	for name, values := range maps {
-		if err := d.decodeParam(ctx, name, values); err != nil {
-			return nil
+		if err := d.decodeParam(ctx, name, values, true); err != nil {
+			return err
 		}
 	}
 
 	for _, idx := range d.sinfo.unmarshalerIndexes {
-		fv := d.v.FieldByIndex(idx)
+		fv := fieldByIndex(d.v, idx)
 		if fv.Kind() == reflect.Struct {
 			fv = fv.Addr()
 		} else if fv.IsNil() {
@@ -72,47 +114,79 @@ func (d *structDecoder) Decode(ctx context.Context, values url.Values) error {
 	}
 
 	if d.sinfo.isUnmarshaler {
-		return d.v.Addr().Interface().(Unmarshaler).UnmarshalValues(ctx, values)
+		u := d.v.Addr().Interface().(Unmarshaler)
+		if err := u.UnmarshalValues(ctx, values); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return d.afterDecode(ctx)
 }
 
-func (d *structDecoder) mapDecoder(name string) *structDecoder {
-	if dec, ok := d.decMap[name]; ok {
-		return dec
-	}
-	if idx, ok := d.sinfo.structs[name]; ok {
-		dec := newStructDecoder(d.v.FieldByIndex(idx))
-		if d.decMap == nil {
-			d.decMap = make(map[string]*structDecoder)
-			d.decMap[name] = dec
-		}
-		return dec
+// decodeParam decodes the values of the param. Pairs reports whether
+// the values are the key and value pairs collected from the `name[key]`
+// params; the map fields are decoded only from them.
+func (d structDecoder) decodeParam(ctx context.Context, name string, values []string, pairs bool) error {
+	if err := d._decodeParam(ctx, name, values, pairs); err != nil {
+		return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
 	}
 	return nil
 }
 
-func (d *structDecoder) decodeParam(ctx context.Context, name string, values []string) error {
-	if err := d._decodeParam(ctx, name, values); err != nil {
-		return fmt.Errorf("urlstruct: can't decode %q: %w", name, err)
+func (d structDecoder) _decodeParam(ctx context.Context, name string, values []string, pairs bool) error {
+	if field := d.sinfo.Field(name); field != nil && !field.noDecode {
+		if field.keyed && !pairs {
+			return nil
+		}
+		return d.decodeField(ctx, field, values)
+	}
+
+	if d.sinfo.isParamUnmarshaler {
+		u := d.v.Addr().Interface().(ParamUnmarshaler)
+		return u.UnmarshalParam(ctx, name, values)
 	}
+
 	return nil
 }
 
-func (d *structDecoder) _decodeParam(ctx context.Context, name string, values []string) error {
-	if field := d.sinfo.Field(name); field != nil && !field.noDecode {
-		return field.scanValue(field.Value(d.v), values)
+// isMap reports whether the param name is of a map field, which are the
+// only fields whose entries count towards Limits.MaxMapEntries.
+func (d structDecoder) isMap(name string) bool {
+	field := d.sinfo.Field(name)
+	return field != nil && field.keyed
+}
+
+// decodeField decodes the field with DecodeURLField or, if it doesn't
+// handle the values, with the setter of the field.
+func (d structDecoder) decodeField(ctx context.Context, field *Field, values []string) error {
+	if d.sinfo.isFieldDecoder {
+		u := d.v.Addr().Interface().(FieldDecoder)
+		if handled, err := u.DecodeURLField(field.Name, values); handled || err != nil {
+			return err
+		}
 	}
+	return field.set(ctx, d.v, values)
+}
 
-	if d.sinfo.isParamUnmarshaler {
-		if d.paramUnmarshaler == nil {
-			d.paramUnmarshaler = d.v.Addr().Interface().(ParamUnmarshaler)
+// fieldByIndex is like reflect.Value.FieldByIndex, but it allocates the nil
+// pointers to embedded structs instead of panicking.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
 		}
-		return d.paramUnmarshaler.UnmarshalParam(ctx, name, values)
+		v = v.Field(x)
 	}
+	return v
+}
 
-	return nil
+func trimParam(name string) string {
+	name = strings.TrimPrefix(name, ":")
+	name = strings.TrimSuffix(name, "[]")
+	return name
 }
 
 func mapKey(s string) (name string, key string, ok bool) {
@@ -2,10 +2,11 @@ package urlstruct
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"reflect"
+	"strings"
 
-	"github.com/codemodus/kace"
 	"github.com/vmihailenco/tagparser"
 )
 
@@ -17,36 +18,132 @@ type ParamUnmarshaler interface {
 	UnmarshalParam(ctx context.Context, name string, values []string) error
 }
 
+// FieldDecoder decodes the struct fields that need custom parsing.
+// DecodeURLField is called with the param name of the field before
+// the field is decoded. If it reports that the values are handled,
+// the field is not decoded.
+type FieldDecoder interface {
+	DecodeURLField(name string, values []string) (handled bool, err error)
+}
+
 //------------------------------------------------------------------------------
 
 type StructInfo struct {
 	fields   []*Field
 	fieldMap map[string]*Field
 
-	structs map[string][]int
+	aliases    map[string]fieldName
+	folded     map[string]fieldName
+	ignoreCase bool
+	conflicts  []string
+
+	structs map[string]*nestedStruct
+	nested  []*nestedStruct // candidates for structs
 
 	isUnmarshaler      bool
 	isParamUnmarshaler bool
+	isFieldDecoder     bool
 	unmarshalerIndexes [][]int
+
+	beforeHooks [][]int // preorder
+	afterHooks  [][]int // postorder
+
+	limits Limits
+
+	skipped   []SkippedField
+	tagErr    error // the first field skipped because of its tag
+	err       error
+	strictErr error // err for the tagged fields only
+}
+
+// SkippedField is an exported struct field that is not decoded.
+type SkippedField struct {
+	Name   string
+	Type   reflect.Type
+	Reason string
+
+	tagged bool
+}
+
+func (f SkippedField) String() string {
+	return "field " + f.Name + " has " + f.Reason
 }
 
-func newStructInfo(typ reflect.Type) *StructInfo {
+// nestedStruct is a struct field that is decoded from `name[key]` params.
+type nestedStruct struct {
+	name   string
+	tagged bool
+	index  []int
+	sinfo  *StructInfo
+}
+
+func newStructInfo(d *Decoder, typ reflect.Type) *StructInfo {
 	sinfo := &StructInfo{
 		fields:   make([]*Field, 0, typ.NumField()),
 		fieldMap: make(map[string]*Field),
 
-		isUnmarshaler:      isUnmarshaler(reflect.PtrTo(typ)),
+		isUnmarshaler:      isHookUnmarshaler(reflect.PtrTo(typ)),
 		isParamUnmarshaler: isParamUnmarshaler(reflect.PtrTo(typ)),
+		isFieldDecoder:     reflect.PtrTo(typ).Implements(fieldDecoderType),
+
+		limits: d.Limits.resolve(),
 	}
-	addFields(sinfo, typ, nil)
+	hooks := typeHooks(typ)
+	if hooks.before {
+		sinfo.beforeHooks = append(sinfo.beforeHooks, nil)
+	}
+	addFields(d, sinfo, typ, nil, hooks)
+	if hooks.after {
+		sinfo.afterHooks = append(sinfo.afterHooks, nil)
+	}
+	sinfo.resolveDuplicates(typ)
+	sinfo.initNames(d.IgnoreCase)
+	sinfo.err = sinfo.validate(typ, false)
+	sinfo.strictErr = sinfo.validate(typ, true)
 	return sinfo
 }
 
+// Field returns the field with the name or alias. If the decoder ignores
+// case, the name is matched case-insensitively.
 func (s *StructInfo) Field(name string) *Field {
-	return s.fieldMap[name]
+	f, _ := s.lookup(name)
+	return f
+}
+
+// Fields returns the decoded fields in the declaration order.
+func (s *StructInfo) Fields() []*Field {
+	return s.fields
 }
 
-func addFields(sinfo *StructInfo, typ reflect.Type, baseIndex []int) {
+// SkippedFields returns the exported fields that are not decoded, including
+// the fields of embedded structs, together with the reason they are skipped.
+// Fields tagged with `urlstruct:"-"` are not included.
+func (s *StructInfo) SkippedFields() []SkippedField {
+	return s.skipped
+}
+
+func (s *StructInfo) skip(sf reflect.StructField, reason string) {
+	_, tagged := sf.Tag.Lookup("urlstruct")
+	s.skipped = append(s.skipped, SkippedField{
+		Name:   sf.Name,
+		Type:   sf.Type,
+		Reason: reason,
+		tagged: tagged,
+	})
+}
+
+// skipTag skips the field with an invalid tag.
+func (s *StructInfo) skipTag(sf reflect.StructField, reason string) {
+	s.skip(sf, reason)
+	if s.tagErr == nil {
+		s.tagErr = fmt.Errorf("urlstruct: %s", s.skipped[len(s.skipped)-1])
+	}
+}
+
+// addFields adds the fields of the struct and its embedded structs. The
+// called hooks are the hooks of the struct that are already called,
+// including the hooks promoted from the embedded structs.
+func addFields(d *Decoder, sinfo *StructInfo, typ reflect.Type, baseIndex []int, called hookSet) {
 	for i := 0; i < typ.NumField(); i++ {
 		sf := typ.Field(i)
 		if sf.PkgPath != "" && !sf.Anonymous {
@@ -64,22 +161,35 @@ func addFields(sinfo *StructInfo, typ reflect.Type, baseIndex []int) {
 				sfType = sfType.Elem()
 			}
 			if sfType.Kind() != reflect.Struct {
+				sinfo.skip(sf, "unsupported embedded type "+sf.Type.String())
 				continue
 			}
 
-			if isUnmarshaler(reflect.PtrTo(sfType)) {
-				index := joinIndex(baseIndex, sf.Index)
+			index := joinIndex(baseIndex, sf.Index)
+			if isHookUnmarshaler(reflect.PtrTo(sfType)) {
 				sinfo.unmarshalerIndexes = append(sinfo.unmarshalerIndexes, index)
 			}
 
-			addFields(sinfo, sfType, joinIndex(baseIndex, sf.Index))
+			// The hooks that are not promoted, e.g. because two embedded
+			// structs define them, are called by the field index.
+			hooks := typeHooks(sfType)
+			if hooks.before && !called.before {
+				sinfo.beforeHooks = append(sinfo.beforeHooks, index)
+			}
+			addFields(d, sinfo, sfType, index, hookSet{
+				before: called.before || hooks.before,
+				after:  called.after || hooks.after,
+			})
+			if hooks.after && !called.after {
+				sinfo.afterHooks = append(sinfo.afterHooks, index)
+			}
 		} else {
-			addField(sinfo, sf, baseIndex)
+			addField(d, sinfo, typ, sf, baseIndex)
 		}
 	}
 }
 
-func addField(sinfo *StructInfo, sf reflect.StructField, baseIndex []int) {
+func addField(d *Decoder, sinfo *StructInfo, typ reflect.Type, sf reflect.StructField, baseIndex []int) {
 	tag := tagparser.Parse(sf.Tag.Get("urlstruct"))
 	if tag.Name == "-" {
 		return
@@ -88,32 +198,103 @@ func addField(sinfo *StructInfo, sf reflect.StructField, baseIndex []int) {
 	name := tag.Name
 	if name == "" {
 		name = sf.Name
+	} else if !isValidName(name) {
+		sinfo.skipTag(sf, fmt.Sprintf("invalid name %q", name))
+		return
+	}
+	if err := checkOptions(sf.Type, tag); err != nil {
+		sinfo.skipTag(sf, err.Error())
+		return
 	}
 	index := joinIndex(baseIndex, sf.Index)
 
 	if sf.Type.Kind() == reflect.Struct {
-		if sinfo.structs == nil {
-			sinfo.structs = make(map[string][]int)
+		nested := d.describeNested(sf.Type)
+		sinfo.nested = append(sinfo.nested, &nestedStruct{
+			name:   d.paramName(name),
+			tagged: tag.Name != "",
+			index:  index,
+			sinfo:  nested,
+		})
+		sinfo.addNestedHooks(index, nested)
+		if sinfo.tagErr == nil {
+			sinfo.tagErr = nested.tagErr
 		}
-		sinfo.structs[kace.Snake(name)] = append(baseIndex, sf.Index...)
 	}
 
-	if isUnmarshaler(reflect.PtrTo(sf.Type)) {
+	isHook := isHookUnmarshaler(reflect.PtrTo(sf.Type))
+	if isHook {
 		sinfo.unmarshalerIndexes = append(sinfo.unmarshalerIndexes, index)
 	}
 
 	f := &Field{
-		Type:  sf.Type,
-		Name:  kace.Snake(name),
-		Index: index,
-		Tag:   tag,
+		Type:    sf.Type,
+		Name:    d.paramName(name),
+		Aliases: tagAliases(tag),
+		Index:   index,
+		Tag:     tag,
+
+		goName: sf.Name,
+		tagged: tag.Name != "",
 	}
-	f.init()
+	if method, ok := tag.Options["decoder"]; ok {
+		decode, err := decoderMethod(typ, baseIndex, strings.Trim(method, "'"))
+		if err != nil {
+			sinfo.skipTag(sf, err.Error())
+			return
+		}
+		f.decode = decode
+	}
+	f.init(d)
 
-	if f.scanValue != nil {
+	if f.set != nil {
 		sinfo.fields = append(sinfo.fields, f)
 		sinfo.fieldMap[f.Name] = f
+	} else if sf.Type.Kind() != reflect.Struct && !isHook {
+		sinfo.skip(sf, "unsupported type "+sf.Type.String())
+	}
+}
+
+// checkOptions reports the tag options with unknown values and invalid enums.
+func checkOptions(typ reflect.Type, tag *tagparser.Tag) error {
+	if unit, ok := tag.Options["unit"]; ok {
+		if _, ok := timeUnits[unit]; !ok {
+			return fmt.Errorf("invalid unit %q", unit)
+		}
+	}
+	for _, alias := range tagAliases(tag) {
+		if !isValidName(alias) {
+			return fmt.Errorf("invalid alias %q", alias)
+		}
+	}
+	if policy, ok := tag.Options["repeat"]; ok {
+		if _, ok := repeatPolicies[policy]; !ok {
+			return fmt.Errorf("invalid repeat policy %q", policy)
+		}
+	}
+	if format, ok := tag.Options["duration"]; ok {
+		if _, ok := durationFormats[format]; !ok {
+			return fmt.Errorf("invalid duration format %q", format)
+		}
+	}
+	if _, err := fieldEnum(typ, tag); err != nil {
+		return err
 	}
+	return nil
+}
+
+// isValidName reports whether the name can be used as a param name.
+func isValidName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "[]") && !strings.HasPrefix(name, ":")
+}
+
+// tagAliases returns the aliases declared with `alias:a|b`.
+func tagAliases(tag *tagparser.Tag) []string {
+	s, ok := tag.Options["alias"]
+	if !ok {
+		return nil
+	}
+	return strings.Split(strings.Trim(s, "'"), "|")
 }
 
 func joinIndex(base, idx []int) []int {
@@ -129,9 +310,10 @@ func joinIndex(base, idx []int) []int {
 //------------------------------------------------------------------------------
 
 var (
-	contextType   = reflect.TypeOf((*context.Context)(nil)).Elem()
-	urlValuesType = reflect.TypeOf((*url.Values)(nil)).Elem()
-	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	contextType      = reflect.TypeOf((*context.Context)(nil)).Elem()
+	urlValuesType    = reflect.TypeOf((*url.Values)(nil)).Elem()
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+	fieldDecoderType = reflect.TypeOf((*FieldDecoder)(nil)).Elem()
 )
 
 func isUnmarshaler(typ reflect.Type) bool {
@@ -149,6 +331,18 @@ func isUnmarshaler(typ reflect.Type) bool {
 	return false
 }
 
+// isHookUnmarshaler reports whether UnmarshalValues should be called after
+// the fields are decoded. Generated unmarshalers decode the same params
+// as the reflection-based decoder and are not called again.
+func isHookUnmarshaler(typ reflect.Type) bool {
+	return isUnmarshaler(typ) && !isGeneratedUnmarshaler(typ)
+}
+
+func isGeneratedUnmarshaler(typ reflect.Type) bool {
+	meth, ok := typ.MethodByName("URLStructGenerated")
+	return ok && meth.Type.NumIn() == 1 && meth.Type.NumOut() == 0
+}
+
 var (
 	stringType      = reflect.TypeOf("")
 	stringSliceType = reflect.TypeOf((*[]string)(nil)).Elem()
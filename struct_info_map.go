@@ -6,31 +6,39 @@ import (
 	"sync"
 )
 
-var globalMap structInfoMap
-
+// DescribeStruct returns the description of the struct that Unmarshal uses.
+// It panics if typ is not a struct or a pointer to a struct and for structs
+// with conflicting names; Decoder.DescribeStruct returns the error instead.
 func DescribeStruct(typ reflect.Type) *StructInfo {
-	return globalMap.DescribeStruct(typ)
+	sinfo, err := defaultDecoder.DescribeStruct(typ)
+	if err != nil {
+		panic(err)
+	}
+	return sinfo
 }
 
 type structInfoMap struct {
 	m sync.Map
 }
 
-func (m *structInfoMap) DescribeStruct(typ reflect.Type) *StructInfo {
+func (m *structInfoMap) describeStruct(d *Decoder, typ reflect.Type) (*StructInfo, error) {
+	if typ == nil {
+		return nil, &InvalidUnmarshalError{}
+	}
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
 	}
 	if typ.Kind() != reflect.Struct {
-		panic(fmt.Errorf("got %s, wanted %s", typ.Kind(), reflect.Struct))
+		return nil, fmt.Errorf("urlstruct: got %s, wanted %s", typ.Kind(), reflect.Struct)
 	}
 
 	if v, ok := m.m.Load(typ); ok {
-		return v.(*StructInfo)
+		return v.(*StructInfo), nil
 	}
 
-	sinfo := newStructInfo(typ)
+	sinfo := newStructInfo(d, typ)
 	if v, loaded := m.m.LoadOrStore(typ, sinfo); loaded {
-		return v.(*StructInfo)
+		return v.(*StructInfo), nil
 	}
-	return sinfo
+	return sinfo, nil
 }
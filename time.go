@@ -0,0 +1,300 @@
+package urlstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+type locationKey struct{}
+
+// WithLocation returns a copy of the context that makes the decoder
+// interpret times without an explicit UTC offset in the location, for
+// example, in the time zone of the current user. It takes precedence
+// over Decoder.Location.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+func contextLocation(ctx context.Context) *time.Location {
+	if ctx == nil {
+		return nil
+	}
+	loc, _ := ctx.Value(locationKey{}).(*time.Location)
+	return loc
+}
+
+type clockKey struct{}
+
+// WithClock returns a copy of the context with the clock used to resolve
+// relative time expressions like now-7d. By default it is time.Now.
+func WithClock(ctx context.Context, now func() time.Time) context.Context {
+	return context.WithValue(ctx, clockKey{}, now)
+}
+
+func contextNow(ctx context.Context) time.Time {
+	if ctx != nil {
+		if now, ok := ctx.Value(clockKey{}).(func() time.Time); ok {
+			return now()
+		}
+	}
+	return time.Now()
+}
+
+var timeUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
+// timeParser parses time.Time values using the field tag options:
+//
+//   - `layout:2006-01-02` parses values with the layout. Layouts with
+//     commas must be quoted, e.g. `layout:'Mon, 02 Jan 2006'`.
+//   - `unit:ms` interprets integer values as milliseconds since the Unix
+//     epoch. Supported units are s (default), ms, us and ns.
+//   - `relative` accepts relative expressions like now-7d, see
+//     parseRelativeTime.
+type timeParser struct {
+	layout   string
+	unit     time.Duration
+	loc      *time.Location
+	relative bool
+}
+
+var defaultTimeParser = &timeParser{
+	unit: time.Second,
+}
+
+func (d *Decoder) timeParser(tag *tagparser.Tag) *timeParser {
+	p := &timeParser{
+		unit:     time.Second,
+		loc:      d.Location,
+		relative: d.RelativeTime || hasOption(tag, "relative"),
+	}
+	if tag == nil {
+		return p
+	}
+	if layout, ok := tag.Options["layout"]; ok {
+		p.layout = strings.Trim(layout, "'")
+	}
+	if unit, ok := timeUnits[tag.Options["unit"]]; ok {
+		p.unit = unit
+	}
+	return p
+}
+
+func (p *timeParser) scan(ctx context.Context, v reflect.Value, values []string) error {
+	tm, err := p.parse(ctx, values[0])
+	if err != nil {
+		return err
+	}
+	if p, ok := ptr(v).(*time.Time); ok {
+		*p = tm
+		return nil
+	}
+	v.Set(reflect.ValueOf(tm))
+	return nil
+}
+
+func (p *timeParser) parse(ctx context.Context, s string) (time.Time, error) {
+	loc := contextLocation(ctx)
+	if loc == nil {
+		loc = p.loc
+	}
+	if p.relative && !isInteger(s) {
+		now := contextNow(ctx)
+		if loc != nil {
+			now = now.In(loc)
+		}
+		if tm, ok, err := parseRelativeTime(s, now); ok {
+			return tm, err
+		}
+	}
+	if p.layout != "" {
+		return parseInLocation(p.layout, s, loc)
+	}
+	return parseTimeIn(s, p.unit, loc)
+}
+
+func (p *timeParser) scanRange(ctx context.Context, v reflect.Value, values []string) error {
+	r, err := p.parseRange(ctx, values[0])
+	if err != nil {
+		return err
+	}
+	if p, ok := ptr(v).(*TimeRange); ok {
+		*p = r
+		return nil
+	}
+	v.Set(reflect.ValueOf(r))
+	return nil
+}
+
+func (p *timeParser) parseRange(ctx context.Context, s string) (TimeRange, error) {
+	start, end, ok := strings.Cut(s, "..")
+	if !ok || (start == "" && end == "") {
+		return TimeRange{}, fmt.Errorf("invalid time range %q", s)
+	}
+
+	var r TimeRange
+	var err error
+	if start != "" {
+		if r.Start, err = p.parse(ctx, start); err != nil {
+			return TimeRange{}, err
+		}
+	}
+	if end != "" {
+		if r.End, err = p.parse(ctx, end); err != nil {
+			return TimeRange{}, err
+		}
+	}
+
+	if start != "" && end != "" && r.End.Before(r.Start) {
+		return TimeRange{}, fmt.Errorf("time range %q ends before it starts", s)
+	}
+	return r, nil
+}
+
+// parseRelativeTime resolves expressions like now, today, yesterday,
+// tomorrow, now-7d, today+1w and -24h against now. Offsets use the
+// time.ParseDuration syntax or a number of calendar days (d), weeks (w),
+// months (M) or years (y). It reports false if s is not a relative
+// expression.
+func parseRelativeTime(s string, now time.Time) (time.Time, bool, error) {
+	var base time.Time
+	offset := s
+	switch {
+	case strings.HasPrefix(s, "now"):
+		base, offset = now, s[len("now"):]
+	case strings.HasPrefix(s, "today"):
+		base, offset = startOfDay(now), s[len("today"):]
+	case strings.HasPrefix(s, "yesterday"):
+		base, offset = startOfDay(now).AddDate(0, 0, -1), s[len("yesterday"):]
+	case strings.HasPrefix(s, "tomorrow"):
+		base, offset = startOfDay(now).AddDate(0, 0, 1), s[len("tomorrow"):]
+	case s != "" && (s[0] == '-' || s[0] == '+'):
+		base = now
+	default:
+		return time.Time{}, false, nil
+	}
+
+	if offset == "" {
+		return base, true, nil
+	}
+	if offset[0] != '-' && offset[0] != '+' {
+		return time.Time{}, true, fmt.Errorf("invalid relative time %q", s)
+	}
+
+	tm, err := addOffset(base, offset)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("invalid relative time %q", s)
+	}
+	return tm, true, nil
+}
+
+func addOffset(tm time.Time, offset string) (time.Time, error) {
+	num, unit := offset[:len(offset)-1], offset[len(offset)-1]
+	switch unit {
+	case 'd', 'w', 'M', 'y':
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return time.Time{}, err
+		}
+		switch unit {
+		case 'd':
+			return tm.AddDate(0, 0, n), nil
+		case 'w':
+			return tm.AddDate(0, 0, 7*n), nil
+		case 'M':
+			return tm.AddDate(0, n, 0), nil
+		default:
+			return tm.AddDate(n, 0, 0), nil
+		}
+	}
+
+	dur, err := time.ParseDuration(offset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return tm.Add(dur), nil
+}
+
+func startOfDay(tm time.Time) time.Time {
+	year, month, day := tm.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, tm.Location())
+}
+
+func parseTime(s string) (time.Time, error) {
+	return parseTimeIn(s, time.Second, nil)
+}
+
+// parseTimeIn parses Unix timestamps in the unit and the supported formats.
+// A nil loc keeps the time.Parse behavior.
+func parseTimeIn(s string, unit time.Duration, loc *time.Location) (time.Time, error) {
+	if isInteger(s) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			tm := unixTime(n, unit)
+			if loc != nil {
+				tm = tm.In(loc)
+			}
+			return tm, nil
+		}
+	}
+
+	const dateFormat = "2006-01-02"
+	if len(s) == len(dateFormat) && s[4] == '-' {
+		return parseInLocation(dateFormat, s, loc)
+	}
+
+	if len(s) >= 5 && s[4] == '-' {
+		if !hasZone(s) {
+			const localFormat = "2006-01-02T15:04:05.999999999"
+			return parseInLocation(localFormat, s, loc)
+		}
+		return parseInLocation(time.RFC3339Nano, s, loc)
+	}
+
+	if len(s) == 15 {
+		const basicFormat = "20060102T150405"
+		return parseInLocation(basicFormat, s, loc)
+	}
+
+	const basicFormat = "20060102T150405-07:00"
+	return parseInLocation(basicFormat, s, loc)
+}
+
+// hasZone reports whether the RFC 3339 time ends with Z or an UTC offset.
+func hasZone(s string) bool {
+	const dateTime = "2006-01-02T15:04:05"
+	if len(s) <= len(dateTime) {
+		return false
+	}
+	return s[len(s)-1] == 'Z' || strings.ContainsAny(s[len(dateTime):], "+-")
+}
+
+func parseInLocation(layout, s string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		return time.Parse(layout, s)
+	}
+	return time.ParseInLocation(layout, s, loc)
+}
+
+func unixTime(n int64, unit time.Duration) time.Time {
+	switch unit {
+	case time.Millisecond:
+		return time.UnixMilli(n)
+	case time.Microsecond:
+		return time.UnixMicro(n)
+	case time.Nanosecond:
+		return time.Unix(0, n)
+	default:
+		return time.Unix(n, 0)
+	}
+}
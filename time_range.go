@@ -0,0 +1,42 @@
+package urlstruct
+
+import (
+	"time"
+)
+
+// TimeRange is a time interval decoded from values like
+// 2024-01-01..2024-02-01 or now-7d..now. Either bound can be omitted,
+// e.g. 2024-01-01.., which leaves it zero and the range open on that side.
+// The bounds are parsed with the same tag options as time.Time fields.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsZero reports whether both bounds are zero.
+func (r TimeRange) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero()
+}
+
+// Contains reports whether the time is in the half-open range [Start, End).
+func (r TimeRange) Contains(tm time.Time) bool {
+	if !r.Start.IsZero() && tm.Before(r.Start) {
+		return false
+	}
+	if !r.End.IsZero() && !tm.Before(r.End) {
+		return false
+	}
+	return true
+}
+
+// String returns the range in the format accepted by the decoder.
+func (r TimeRange) String() string {
+	var start, end string
+	if !r.Start.IsZero() {
+		start = r.Start.Format(time.RFC3339Nano)
+	}
+	if !r.End.IsZero() {
+		end = r.End.Format(time.RFC3339Nano)
+	}
+	return start + ".." + end
+}
@@ -0,0 +1,242 @@
+package urlstruct_test
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type TimeFilter struct {
+	Time     time.Time
+	Day      time.Time   `urlstruct:",layout:2006-01-02"`
+	Local    time.Time   `urlstruct:",layout:'2006-01-02 15:04'"`
+	Millis   time.Time   `urlstruct:",unit:ms"`
+	Micros   []time.Time `urlstruct:",unit:us"`
+	Birthday urlstruct.Date
+	Days     []urlstruct.Date
+}
+
+type InvalidUnitFilter struct {
+	Time time.Time `urlstruct:",unit:h"`
+}
+
+var _ = Describe("Time scanners", func() {
+	ctx := context.TODO()
+
+	var berlin *time.Location
+
+	BeforeEach(func() {
+		var err error
+		berlin, err = time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("decodes date-only values", func() {
+		f := new(TimeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"time": {"2024-03-01"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Time).To(Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("uses the layout and unit tags", func() {
+		f := new(TimeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"day":    {"2024-03-01"},
+			"local":  {"2024-03-01 10:30"},
+			"millis": {"1500"},
+			"micros": {"1500000", "-1"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Day).To(Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(f.Local).To(Equal(time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)))
+		Expect(f.Millis).To(Equal(time.Unix(1, 5e8)))
+		Expect(f.Micros).To(Equal([]time.Time{time.Unix(1, 5e8), time.Unix(0, -1e3)}))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"day": {"2024-03-01T00:00:00Z"}}, f)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("interprets times in the decoder location", func() {
+		d := &urlstruct.Decoder{Location: berlin}
+
+		f := new(TimeFilter)
+		err := d.Unmarshal(ctx, url.Values{
+			"time":   {"2024-03-01T10:00:00"},
+			"local":  {"2024-03-01 10:30"},
+			"millis": {"0"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Time.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))).To(BeTrue())
+		Expect(f.Local.Equal(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC))).To(BeTrue())
+		Expect(f.Millis.Location()).To(Equal(berlin))
+		Expect(f.Millis.Unix()).To(Equal(int64(0)))
+
+		err = d.Unmarshal(ctx, url.Values{"time": {"2024-03-01T10:00:00Z"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Time.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	It("prefers the location from the context", func() {
+		d := &urlstruct.Decoder{Location: time.UTC}
+		ctx := urlstruct.WithLocation(ctx, berlin)
+
+		f := new(TimeFilter)
+		err := d.Unmarshal(ctx, url.Values{"day": {"2024-07-01"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Day.Equal(time.Date(2024, 6, 30, 22, 0, 0, 0, time.UTC))).To(BeTrue())
+
+		var tm time.Time
+		err = urlstruct.Scan(ctx, &tm, []string{"2024-07-01"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tm.Location()).To(Equal(berlin))
+	})
+
+	It("decodes dates", func() {
+		f := new(TimeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"birthday": {"1990-12-31"},
+			"days":     {"2024-02-29", "2024-03-01"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Birthday).To(Equal(urlstruct.Date{Year: 1990, Month: time.December, Day: 31}))
+		Expect(f.Birthday.String()).To(Equal("1990-12-31"))
+		Expect(f.Days).To(Equal([]urlstruct.Date{
+			{Year: 2024, Month: time.February, Day: 29},
+			{Year: 2024, Month: time.March, Day: 1},
+		}))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"birthday": {"2023-02-29"}}, f)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("converts dates to times", func() {
+		date := urlstruct.Date{Year: 2024, Month: time.March, Day: 1}
+		Expect(date.In(berlin)).To(Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, berlin)))
+		Expect(urlstruct.DateOf(date.In(berlin))).To(Equal(date))
+		Expect(urlstruct.Date{}.IsZero()).To(BeTrue())
+	})
+
+	It("rejects unknown units", func() {
+		err := urlstruct.Validate(reflect.TypeOf(InvalidUnitFilter{}))
+		Expect(err).To(MatchError(`urlstruct: invalid urlstruct_test.InvalidUnitFilter: ` +
+			`field Time has invalid unit "h"`))
+	})
+})
+
+type RelativeFilter struct {
+	Since    time.Time `urlstruct:",relative"`
+	Absolute time.Time
+	Period   urlstruct.TimeRange `urlstruct:",relative"`
+	Periods  []urlstruct.TimeRange
+}
+
+var _ = Describe("Relative times", func() {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	ctx := urlstruct.WithClock(context.TODO(), func() time.Time { return now })
+
+	It("resolves relative expressions against the clock", func() {
+		tests := []struct {
+			value  string
+			wanted time.Time
+		}{
+			{"now", now},
+			{"now-7d", now.AddDate(0, 0, -7)},
+			{"now+1w", now.AddDate(0, 0, 7)},
+			{"now-1M", time.Date(2024, 2, 10, 15, 30, 0, 0, time.UTC)},
+			{"now-1y", time.Date(2023, 3, 10, 15, 30, 0, 0, time.UTC)},
+			{"-24h", now.Add(-24 * time.Hour)},
+			{"now-1h30m", now.Add(-90 * time.Minute)},
+			{"today", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+			{"today+1d", time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+			{"yesterday", time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)},
+			{"tomorrow", time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+			{"2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		}
+		for _, test := range tests {
+			f := new(RelativeFilter)
+			err := urlstruct.Unmarshal(ctx, url.Values{"since": {test.value}}, f)
+			Expect(err).NotTo(HaveOccurred(), test.value)
+			Expect(f.Since).To(Equal(test.wanted), test.value)
+		}
+	})
+
+	It("keeps integers as Unix timestamps", func() {
+		f := new(RelativeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"since": {"-1"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Since).To(Equal(time.Unix(-1, 0)))
+	})
+
+	It("rejects invalid expressions", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"since": {"now-7x"}}, new(RelativeFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "since": invalid relative time "now-7x"`))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"since": {"today1d"}}, new(RelativeFilter))
+		Expect(err).To(MatchError(`urlstruct: can't decode "since": invalid relative time "today1d"`))
+	})
+
+	It("is opt-in", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{"absolute": {"now"}}, new(RelativeFilter))
+		Expect(err).To(HaveOccurred())
+
+		d := &urlstruct.Decoder{RelativeTime: true}
+		f := new(RelativeFilter)
+		err = d.Unmarshal(ctx, url.Values{"absolute": {"now"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Absolute).To(Equal(now))
+	})
+
+	It("resolves today in the location", func() {
+		loc := time.FixedZone("UTC+10", 10*60*60)
+		ctx := urlstruct.WithLocation(ctx, loc)
+
+		f := new(RelativeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"since": {"today"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Since).To(Equal(time.Date(2024, 3, 11, 0, 0, 0, 0, loc)))
+	})
+
+	It("decodes time ranges", func() {
+		f := new(RelativeFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"period":  {"now-7d..now"},
+			"periods": {"2024-01-01..2024-02-01", "2024-01-01..", "..2024-02-01"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(f.Period).To(Equal(urlstruct.TimeRange{Start: now.AddDate(0, 0, -7), End: now}))
+		Expect(f.Period.Contains(now.Add(-time.Hour))).To(BeTrue())
+		Expect(f.Period.Contains(now)).To(BeFalse())
+
+		jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		Expect(f.Periods).To(Equal([]urlstruct.TimeRange{
+			{Start: jan, End: feb},
+			{Start: jan},
+			{End: feb},
+		}))
+		Expect(f.Periods[0].String()).To(Equal("2024-01-01T00:00:00Z..2024-02-01T00:00:00Z"))
+		Expect(f.Periods[1].Contains(now)).To(BeTrue())
+	})
+
+	It("rejects invalid time ranges", func() {
+		tests := []struct {
+			value string
+			err   string
+		}{
+			{"2024-01-01", `invalid time range "2024-01-01"`},
+			{"..", `invalid time range ".."`},
+			{"2024-02-01..2024-01-01", `time range "2024-02-01..2024-01-01" ends before it starts`},
+		}
+		for _, test := range tests {
+			err := urlstruct.Unmarshal(ctx, url.Values{"periods": {test.value}}, new(RelativeFilter))
+			Expect(err).To(MatchError(`urlstruct: can't decode "periods": ` + test.err))
+		}
+	})
+})
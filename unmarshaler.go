@@ -0,0 +1,86 @@
+package urlstruct
+
+import (
+	"context"
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/vmihailenco/tagparser"
+)
+
+// unmarshalFunc decodes the value into u, which implements the interface
+// the func was selected for.
+type unmarshalFunc func(u interface{}, s string) error
+
+// unmarshalerFor returns the func that decodes values into the type using
+// its own method. The interfaces are checked in this order:
+//
+//  1. encoding.TextUnmarshaler, which gets the value as is.
+//  2. encoding.BinaryUnmarshaler, which gets the base64-decoded value. It is
+//     used only with the `base64` tag option.
+//  3. flag.Value, which gets the value as is.
+func unmarshalerFor(typ reflect.Type, tag *tagparser.Tag) unmarshalFunc {
+	switch {
+	case typ.Implements(textUnmarshalerType):
+		return unmarshalText
+	case typ.Implements(binaryUnmarshalerType) && hasOption(tag, "base64"):
+		return unmarshalBinary
+	case typ.Implements(flagValueType):
+		return setFlagValue
+	}
+	return nil
+}
+
+// hasUnmarshaler reports whether the type or the pointer to it decodes
+// itself, e.g. a named slice that implements encoding.TextUnmarshaler
+// is decoded from a single value rather than as a slice.
+func hasUnmarshaler(typ reflect.Type, tag *tagparser.Tag) bool {
+	return unmarshalerFor(typ, tag) != nil || unmarshalerFor(reflect.PtrTo(typ), tag) != nil
+}
+
+// unmarshalerScanner returns the scanner for the types that decode
+// themselves. Pointers are allocated when nil. Other types are decoded
+// through a pointer, so the methods with the pointer receiver are used too.
+func unmarshalerScanner(typ reflect.Type, tag *tagparser.Tag) scannerFunc {
+	if typ.Kind() == reflect.Ptr {
+		unmarshal := unmarshalerFor(typ, tag)
+		if unmarshal == nil {
+			return nil
+		}
+		return func(ctx context.Context, v reflect.Value, values []string) error {
+			if v.IsNil() {
+				v.Set(reflect.New(typ.Elem()))
+			}
+			return unmarshal(v.Interface(), values[0])
+		}
+	}
+
+	unmarshal := unmarshalerFor(reflect.PtrTo(typ), tag)
+	if unmarshal == nil {
+		return nil
+	}
+	return func(ctx context.Context, v reflect.Value, values []string) error {
+		if !v.CanAddr() {
+			return fmt.Errorf("urlstruct: Scan(nonsettable %s)", v.Type())
+		}
+		return unmarshal(v.Addr().Interface(), values[0])
+	}
+}
+
+func unmarshalText(u interface{}, s string) error {
+	return u.(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
+
+func unmarshalBinary(u interface{}, s string) error {
+	b, err := decodeBase64(s)
+	if err != nil {
+		return err
+	}
+	return u.(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+}
+
+func setFlagValue(u interface{}, s string) error {
+	return u.(flag.Value).Set(s)
+}
@@ -0,0 +1,240 @@
+package urlstruct_test
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"flag"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+// Blob implements only encoding.BinaryUnmarshaler.
+type Blob []byte
+
+var _ encoding.BinaryUnmarshaler = (*Blob)(nil)
+
+func (b *Blob) UnmarshalBinary(data []byte) error {
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Tags implements only flag.Value.
+type Tags []string
+
+var _ flag.Value = (*Tags)(nil)
+
+func (t *Tags) String() string {
+	return strings.Join(*t, ",")
+}
+
+func (t *Tags) Set(s string) error {
+	if s == "" {
+		return errors.New("empty tags")
+	}
+	*t = strings.Split(s, ",")
+	return nil
+}
+
+// Via implements all three interfaces and records the method that
+// decoded it.
+type Via struct {
+	Method string
+	Value  string
+}
+
+func (v *Via) UnmarshalText(text []byte) error {
+	*v = Via{Method: "text", Value: string(text)}
+	return nil
+}
+
+func (v *Via) UnmarshalBinary(data []byte) error {
+	*v = Via{Method: "binary", Value: string(data)}
+	return nil
+}
+
+func (v *Via) String() string {
+	return v.Value
+}
+
+func (v *Via) Set(s string) error {
+	*v = Via{Method: "flag", Value: s}
+	return nil
+}
+
+// BinaryVia implements encoding.BinaryUnmarshaler and flag.Value.
+type BinaryVia struct {
+	Method string
+	Value  string
+}
+
+func (v *BinaryVia) UnmarshalBinary(data []byte) error {
+	*v = BinaryVia{Method: "binary", Value: string(data)}
+	return nil
+}
+
+func (v *BinaryVia) String() string {
+	return v.Value
+}
+
+func (v *BinaryVia) Set(s string) error {
+	*v = BinaryVia{Method: "flag", Value: s}
+	return nil
+}
+
+type UnmarshalerFilter struct {
+	Via          Via
+	BinaryVia    BinaryVia
+	Base64Via    BinaryVia `urlstruct:",base64"`
+	Blob         Blob      `urlstruct:",base64"`
+	Tags         Tags
+	TagsPtr      *Tags
+	Blobs        []Blob `urlstruct:",base64"`
+	BlobOrString Blob
+
+	Counts  map[uuid.UUID]int
+	Colors  map[Color]CustomField
+	Customs map[string]*CustomField
+	BlobMap map[int]Blob `urlstruct:",base64"`
+	Flags   map[string]bool
+}
+
+var _ = Describe("Unmarshalers", func() {
+	ctx := context.TODO()
+
+	It("prefers TextUnmarshaler, then BinaryUnmarshaler, then flag.Value", func() {
+		f := new(UnmarshalerFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"via":        {"hello"},
+			"binary_via": {"aGVsbG8"},
+			"base64_via": {"aGVsbG8"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Via).To(Equal(Via{Method: "text", Value: "hello"}))
+		Expect(f.BinaryVia).To(Equal(BinaryVia{Method: "flag", Value: "aGVsbG8"}))
+		Expect(f.Base64Via).To(Equal(BinaryVia{Method: "binary", Value: "hello"}))
+	})
+
+	It("uses BinaryUnmarshaler only with the base64 option", func() {
+		f := new(UnmarshalerFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"blob_or_string": {"AQ"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.BlobOrString).To(Equal(Blob("AQ")))
+	})
+
+	It("decodes BinaryUnmarshaler from base64", func() {
+		f := new(UnmarshalerFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"blob":  {"_-8="},
+			"blobs": {"AQ", "Ag=="},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Blob).To(Equal(Blob{0xff, 0xef}))
+		Expect(f.Blobs).To(Equal([]Blob{{1}, {2}}))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"blob": {"!"}}, f)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix(`urlstruct: can't decode "blob": illegal base64 data`))
+	})
+
+	It("decodes flag.Value", func() {
+		f := new(UnmarshalerFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"tags":     {"a,b"},
+			"tags_ptr": {"c"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Tags).To(Equal(Tags{"a", "b"}))
+		Expect(f.TagsPtr).To(Equal(&Tags{"c"}))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"tags": {""}}, f)
+		Expect(err).To(MatchError(`urlstruct: can't decode "tags": empty tags`))
+	})
+
+	It("decodes map keys and values", func() {
+		id := uuid.MustParse("3fa85f64-5717-4562-b3fc-2c963f66afa6")
+		f := new(UnmarshalerFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"counts[" + id.String() + "]": {"3"},
+			"colors[red]":                 {"warm"},
+			"colors[verde]":               {"cold"},
+			"customs[a]":                  {"x"},
+			"blob_map[1]":                 {"AQ"},
+			"flags[archived]":             {""},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Counts).To(Equal(map[uuid.UUID]int{id: 3}))
+		Expect(f.Colors).To(Equal(map[Color]CustomField{
+			Red:   {S: "warm"},
+			Green: {S: "cold"},
+		}))
+		Expect(f.Customs).To(Equal(map[string]*CustomField{"a": {S: "x"}}))
+		Expect(f.BlobMap).To(Equal(map[int]Blob{1: {1}}))
+		Expect(f.Flags).To(Equal(map[string]bool{"archived": true}))
+	})
+
+	It("decodes maps only from the name[key] params", func() {
+		f := new(UnmarshalerFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{
+			"flags":      {"archived", "true"},
+			"flags[]":    {"deleted"},
+			"flags[new]": {"1"},
+		}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Flags).To(Equal(map[string]bool{"new": true}))
+	})
+
+	It("reports invalid map keys and values", func() {
+		f := new(UnmarshalerFilter)
+		err := urlstruct.Unmarshal(ctx, url.Values{"counts[x]": {"1"}}, f)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix(`urlstruct: can't decode "counts": invalid key "x": `))
+
+		err = urlstruct.Unmarshal(ctx, url.Values{"colors[blue]": {"x"}}, f)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix(`urlstruct: can't decode "colors": invalid key "blue": `))
+
+		id := uuid.MustParse("3fa85f64-5717-4562-b3fc-2c963f66afa6")
+		err = urlstruct.Unmarshal(ctx, url.Values{"counts[" + id.String() + "]": {"x"}}, f)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix(`urlstruct: can't decode "counts": key "` + id.String() + `": `))
+	})
+
+	It("skips maps of unsupported types", func() {
+		type Filter struct {
+			Nested map[string]map[string]string
+			Lists  map[string][]string
+			Chans  map[chan int]string
+		}
+		sinfo := urlstruct.DescribeStruct(reflect.TypeOf(Filter{}))
+		Expect(sinfo.SkippedFields()).To(HaveLen(3))
+	})
+
+	It("scans the types with Scan", func() {
+		var blob Blob
+		Expect(urlstruct.Scan(ctx, &blob, []string{"AQ"})).To(Succeed())
+		Expect(blob).To(Equal(Blob("AQ")))
+
+		var tags Tags
+		Expect(urlstruct.Scan(ctx, &tags, []string{"a,b"})).To(Succeed())
+		Expect(tags).To(Equal(Tags{"a", "b"}))
+
+		var m map[Color]int
+		Expect(urlstruct.Scan(ctx, &m, []string{"red", "1"})).To(Succeed())
+		Expect(m).To(Equal(map[Color]int{Red: 1}))
+
+		err := urlstruct.Scan(ctx, &m, []string{"red", "1", "green"})
+		Expect(err).To(MatchError("got 3 values, wanted key and value pairs"))
+
+		var ss map[string]string
+		err = urlstruct.Scan(ctx, &ss, []string{"a"})
+		Expect(err).To(MatchError("got 1 values, wanted key and value pairs"))
+	})
+})
@@ -6,8 +6,83 @@ import (
 	"reflect"
 )
 
-// Unmarshal unmarshals the URL query values into the struct.
+// Unmarshal unmarshals the URL query values into the struct using the
+// default Decoder.
 func Unmarshal(ctx context.Context, values url.Values, strct interface{}) error {
-	d := newStructDecoder(reflect.ValueOf(strct))
-	return d.Decode(ctx, values)
+	return defaultDecoder.Unmarshal(ctx, values, strct)
+}
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "urlstruct: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Ptr {
+		return "urlstruct: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	if e.Type.Elem().Kind() != reflect.Struct {
+		return "urlstruct: Unmarshal(non-struct " + e.Type.String() + ")"
+	}
+	return "urlstruct: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+func isStructPtr(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct
+}
+
+func isNilPtr(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// GeneratedUnmarshaler is implemented by the decoders generated with
+// urlstruct-gen. Unmarshal calls UnmarshalValues directly for such types
+// and skips the reflection-based decoder.
+type GeneratedUnmarshaler interface {
+	Unmarshaler
+	URLStructGenerated()
+}
+
+// DecodeParam decodes a single param into the struct field with the given name.
+// Map fields expect the key and value pairs. Unlike Unmarshal, it does not add
+// the param name to the returned error.
+func DecodeParam(ctx context.Context, strct interface{}, name string, values []string) error {
+	return decodeParam(ctx, strct, name, values, true)
+}
+
+// DecodeNestedParam is like DecodeParam, but it decodes the value of
+// a `sub[name]` param into the nested struct. Like in Unmarshal, such
+// params are not decoded into map fields. It is used by the decoders
+// generated with urlstruct-gen.
+func DecodeNestedParam(ctx context.Context, strct interface{}, name string, values []string) error {
+	return decodeParam(ctx, strct, name, values, false)
+}
+
+func decodeParam(ctx context.Context, strct interface{}, name string, values []string, pairs bool) error {
+	v := reflect.ValueOf(strct)
+	if !isStructPtr(v) {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(strct)}
+	}
+	sinfo, err := defaultDecoder.DescribeStruct(v.Type())
+	if err != nil {
+		return err
+	}
+	d := structDecoder{
+		v:     v.Elem(),
+		sinfo: sinfo,
+	}
+	return d._decodeParam(ctx, name, values, pairs)
+}
+
+// SplitParam strips the optional `:` prefix and `[]` suffix from the param
+// name and splits names like `name[key]` into the name and the key.
+func SplitParam(s string) (name string, key string) {
+	s = trimParam(s)
+	if name, key, ok := mapKey(s); ok {
+		return name, key
+	}
+	return s, ""
 }
@@ -0,0 +1,74 @@
+package urlstruct
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Validate checks the tags and field types of the struct. It reports the
+// fields that Unmarshal silently ignores, for example, because there is no
+// scanner for their types, and conflicting aliases. Validate is meant to be
+// called at startup.
+func Validate(typ reflect.Type) error {
+	sinfo, err := defaultDecoder.structs.describeStruct(defaultDecoder, typ)
+	if err != nil {
+		return err
+	}
+	return sinfo.err
+}
+
+// validate returns the problems of the struct. Tagged limits the skipped
+// fields to the fields with a `urlstruct` tag, which Decoder.Strict rejects.
+func (s *StructInfo) validate(typ reflect.Type, tagged bool) error {
+	problems := s.problems(nil, tagged)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{
+		Type:     typ,
+		Problems: problems,
+	}
+}
+
+func (s *StructInfo) problems(prefix []string, tagged bool) []string {
+	problems := make([]string, 0, len(s.skipped)+len(s.conflicts))
+	for _, f := range s.skipped {
+		if tagged && !f.tagged {
+			continue
+		}
+		problems = append(problems, withPrefix(prefix, f.String()))
+	}
+	for _, conflict := range s.conflicts {
+		problems = append(problems, withPrefix(prefix, conflict))
+	}
+
+	names := make([]string, 0, len(s.structs))
+	for name := range s.structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		nested := s.structs[name].sinfo
+		problems = append(problems, nested.problems(append(prefix, name), tagged)...)
+	}
+	return problems
+}
+
+func withPrefix(prefix []string, problem string) string {
+	if len(prefix) == 0 {
+		return problem
+	}
+	return strings.Join(prefix, ".") + ": " + problem
+}
+
+// A ValidationError lists the problems found by Validate.
+type ValidationError struct {
+	Type     reflect.Type
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "urlstruct: invalid " + e.Type.String() + ": " + strings.Join(e.Problems, "; ")
+}
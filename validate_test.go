@@ -0,0 +1,169 @@
+package urlstruct_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-pg/urlstruct"
+)
+
+type ValuesUnmarshaler map[string][]string
+
+func (u ValuesUnmarshaler) UnmarshalValues(ctx context.Context, values url.Values) error {
+	for k, v := range values {
+		u[k] = v
+	}
+	return nil
+}
+
+type InvalidFilter struct {
+	Ptr       *[]int
+	Iface     interface{}
+	BadName   string `urlstruct:"bad[name]"`
+	Ignored   *int   `urlstruct:"-"`
+	Nested    InvalidNested
+	Supported []float64
+}
+
+type InvalidNested struct {
+	Chan chan int
+}
+
+var _ = Describe("Unmarshal", func() {
+	ctx := context.TODO()
+
+	It("returns InvalidUnmarshalError", func() {
+		var nilFilter *Filter
+		tests := []struct {
+			strct interface{}
+			err   string
+		}{
+			{nil, "urlstruct: Unmarshal(nil)"},
+			{Filter{}, "urlstruct: Unmarshal(non-pointer urlstruct_test.Filter)"},
+			{new(int), "urlstruct: Unmarshal(non-struct *int)"},
+			{nilFilter, "urlstruct: Unmarshal(nil *urlstruct_test.Filter)"},
+		}
+		for _, test := range tests {
+			err := urlstruct.Unmarshal(ctx, url.Values{}, test.strct)
+			Expect(err).To(MatchError(test.err))
+
+			var invalid *urlstruct.InvalidUnmarshalError
+			Expect(errors.As(err, &invalid)).To(BeTrue())
+		}
+	})
+
+	It("calls non-struct Unmarshaler", func() {
+		u := make(ValuesUnmarshaler)
+		err := urlstruct.Unmarshal(ctx, url.Values{"foo": {"bar"}}, u)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u).To(Equal(ValuesUnmarshaler{"foo": {"bar"}}))
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("accepts supported structs", func() {
+		Expect(urlstruct.Validate(reflect.TypeOf(BenchMediumFilter{}))).NotTo(HaveOccurred())
+	})
+
+	It("reports ignored fields", func() {
+		err := urlstruct.Validate(reflect.TypeOf((*InvalidFilter)(nil)))
+		Expect(err).To(MatchError("urlstruct: invalid urlstruct_test.InvalidFilter: " +
+			"field Ptr has unsupported type *[]int; " +
+			"field Iface has unsupported type interface {}; " +
+			`field BadName has invalid name "bad[name]"; ` +
+			"nested: field Chan has unsupported type chan int"))
+	})
+
+	It("rejects non-struct types", func() {
+		err := urlstruct.Validate(reflect.TypeOf(1))
+		Expect(err).To(MatchError("urlstruct: got int, wanted struct"))
+	})
+
+	It("rejects nil types", func() {
+		err := urlstruct.Validate(nil)
+		Expect(err).To(MatchError("urlstruct: Unmarshal(nil)"))
+
+		_, err = new(urlstruct.Decoder).DescribeStruct(nil)
+		Expect(err).To(MatchError("urlstruct: Unmarshal(nil)"))
+	})
+})
+
+var _ = Describe("StructInfo.SkippedFields", func() {
+	It("lists fields that are not decoded", func() {
+		sinfo := urlstruct.DescribeStruct(reflect.TypeOf(InvalidFilter{}))
+		skipped := sinfo.SkippedFields()
+		Expect(skipped).To(HaveLen(3))
+
+		Expect(skipped[0].Name).To(Equal("Ptr"))
+		Expect(skipped[0].Type).To(Equal(reflect.TypeOf((*[]int)(nil))))
+		Expect(skipped[0].Reason).To(Equal("unsupported type *[]int"))
+
+		Expect(skipped[1].Name).To(Equal("Iface"))
+		Expect(skipped[2].Name).To(Equal("BadName"))
+		Expect(skipped[2].Reason).To(Equal(`invalid name "bad[name]"`))
+	})
+
+	It("is empty for supported structs", func() {
+		sinfo := urlstruct.DescribeStruct(reflect.TypeOf(BenchMediumFilter{}))
+		Expect(sinfo.SkippedFields()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Decoder.Strict", func() {
+	ctx := context.TODO()
+
+	It("rejects structs with skipped tagged fields", func() {
+		dec := &urlstruct.Decoder{Strict: true}
+
+		err := dec.Unmarshal(ctx, url.Values{}, new(InvalidFilter))
+		var verr *urlstruct.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Problems).To(Equal([]string{`field BadName has invalid name "bad[name]"`}))
+
+		_, err = dec.DescribeStruct(reflect.TypeOf(InvalidFilter{}))
+		Expect(err).To(Equal(verr))
+	})
+
+	It("ignores untagged fields that can't be decoded", func() {
+		type Filter struct {
+			Title string
+			Done  chan struct{}
+			Next  func() error
+			Ch    chan int `urlstruct:"ch"`
+		}
+		dec := &urlstruct.Decoder{Strict: true}
+
+		err := dec.Unmarshal(ctx, url.Values{}, new(Filter))
+		var verr *urlstruct.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Problems).To(Equal([]string{"field Ch has unsupported type chan int"}))
+
+		type HelperFilter struct {
+			Title string
+			Done  chan struct{}
+		}
+		f := new(HelperFilter)
+		err = dec.Unmarshal(ctx, url.Values{"title": {"go"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Title).To(Equal("go"))
+	})
+
+	It("decodes supported structs", func() {
+		dec := &urlstruct.Decoder{Strict: true}
+
+		f := new(BenchMediumFilter)
+		err := dec.Unmarshal(ctx, url.Values{"title": {"go"}}, f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Title).To(Equal("go"))
+	})
+
+	It("is disabled by default", func() {
+		err := urlstruct.Unmarshal(ctx, url.Values{}, new(InvalidFilter))
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -24,22 +24,34 @@ func (v Values) Strings(name string) []string {
 }
 
 func (v Values) String(name string) string {
-	values := v.Strings(name)
-	if len(values) == 0 {
-		return ""
+	s, _ := v.Value(name, RepeatFirst)
+	return s
+}
+
+// Value returns the value of the param. Repeated params are reduced to
+// a single value with the policy. The other getters use RepeatFirst; their
+// Value variants, e.g. IntValue, take the policy.
+func (v Values) Value(name string, policy RepeatPolicy) (string, error) {
+	values, err := policy.apply(v[name])
+	if err != nil || len(values) == 0 {
+		return "", err
 	}
-	return values[0]
+	return values[0], nil
 }
 
 func (v Values) Bool(name string) (bool, error) {
+	return v.BoolValue(name, RepeatFirst)
+}
+
+func (v Values) BoolValue(name string, policy RepeatPolicy) (bool, error) {
 	if !v.Has(name) {
 		return false, nil
 	}
-	s := v.String(name)
-	if s == "" {
-		return true, nil
+	s, err := v.Value(name, policy)
+	if err != nil {
+		return false, err
 	}
-	return strconv.ParseBool(s)
+	return defaultBoolParser(s)
 }
 
 func (v Values) MaybeBool(name string) bool {
@@ -48,9 +60,13 @@ func (v Values) MaybeBool(name string) bool {
 }
 
 func (v Values) Int(name string) (int, error) {
-	s := v.String(name)
-	if s == "" {
-		return 0, nil
+	return v.IntValue(name, RepeatFirst)
+}
+
+func (v Values) IntValue(name string, policy RepeatPolicy) (int, error) {
+	s, err := v.Value(name, policy)
+	if err != nil || s == "" {
+		return 0, err
 	}
 	return strconv.Atoi(s)
 }
@@ -61,9 +77,13 @@ func (v Values) MaybeInt(name string) int {
 }
 
 func (v Values) Int64(name string) (int64, error) {
-	s := v.String(name)
-	if s == "" {
-		return 0, nil
+	return v.Int64Value(name, RepeatFirst)
+}
+
+func (v Values) Int64Value(name string, policy RepeatPolicy) (int64, error) {
+	s, err := v.Value(name, policy)
+	if err != nil || s == "" {
+		return 0, err
 	}
 	return strconv.ParseInt(s, 10, 64)
 }
@@ -74,9 +94,13 @@ func (v Values) MaybeInt64(name string) int64 {
 }
 
 func (v Values) Float64(name string) (float64, error) {
-	s := v.String(name)
-	if s == "" {
-		return 0, nil
+	return v.Float64Value(name, RepeatFirst)
+}
+
+func (v Values) Float64Value(name string, policy RepeatPolicy) (float64, error) {
+	s, err := v.Value(name, policy)
+	if err != nil || s == "" {
+		return 0, err
 	}
 	return strconv.ParseFloat(s, 64)
 }
@@ -87,9 +111,13 @@ func (v Values) MaybeFloat64(name string) float64 {
 }
 
 func (v Values) Time(name string) (time.Time, error) {
-	s := v.String(name)
-	if s == "" {
-		return time.Time{}, nil
+	return v.TimeValue(name, RepeatFirst)
+}
+
+func (v Values) TimeValue(name string, policy RepeatPolicy) (time.Time, error) {
+	s, err := v.Value(name, policy)
+	if err != nil || s == "" {
+		return time.Time{}, err
 	}
 	return parseTime(s)
 }
@@ -99,12 +127,18 @@ func (v Values) MaybeTime(name string) time.Time {
 	return tm
 }
 
+// Duration parses the value with DurationAny, which accepts the
+// time.ParseDuration syntax, days like 7d and ISO 8601 durations.
 func (v Values) Duration(name string) (time.Duration, error) {
-	s := v.String(name)
-	if s == "" {
-		return 0, nil
+	return v.DurationValue(name, RepeatFirst)
+}
+
+func (v Values) DurationValue(name string, policy RepeatPolicy) (time.Duration, error) {
+	s, err := v.Value(name, policy)
+	if err != nil || s == "" {
+		return 0, err
 	}
-	return time.ParseDuration(s)
+	return DurationAny.Parse(s)
 }
 
 func (v Values) MaybeDuration(name string) time.Duration {